@@ -0,0 +1,300 @@
+/*
+Copyright © 2025 Neil Patrick Villanueva npdvillanueva@gmail.com
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/drxc00/sweepy/internal/clean"
+	"github.com/drxc00/sweepy/internal/scan"
+	"github.com/drxc00/sweepy/types"
+	"github.com/drxc00/sweepy/utils"
+	"github.com/spf13/cobra"
+)
+
+// cleanCmd deletes node_modules directories without the TUI
+var cleanCmd = &cobra.Command{
+	Use:   "clean [directory]",
+	Short: "Delete node_modules directories without the interactive UI",
+	Long: `Scans the directory and deletes every node_modules directory matching the filters.
+Projects with uncommitted or unpushed git work, or whose repository cannot be read, are skipped unless --include-unsaved is set.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getScanContext(cmd, args)
+
+		reproducibleOnlyFlag, errReproducibleOnlyFlag := cmd.Flags().GetBool("reproducible-only")
+		includeUnsavedFlag, errIncludeUnsavedFlag := cmd.Flags().GetBool("include-unsaved")
+		dryRunFlag, errDryRunFlag := cmd.Flags().GetBool("dry-run")
+		yesFlag, errYesFlag := cmd.Flags().GetBool("yes")
+		archiveFlag, errArchiveFlag := cmd.Flags().GetBool("archive")
+		archiveDirFlag, errArchiveDirFlag := cmd.Flags().GetString("archive-dir")
+		workersFlag, errWorkersFlag := cmd.Flags().GetInt("workers")
+		trashFlag, errTrashFlag := cmd.Flags().GetBool("trash")
+
+		if errReproducibleOnlyFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting reproducible-only flag: %v\n", errReproducibleOnlyFlag)
+			os.Exit(1)
+		}
+
+		if errIncludeUnsavedFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting include-unsaved flag: %v\n", errIncludeUnsavedFlag)
+			os.Exit(1)
+		}
+
+		if errDryRunFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting dry-run flag: %v\n", errDryRunFlag)
+			os.Exit(1)
+		}
+
+		if errYesFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting yes flag: %v\n", errYesFlag)
+			os.Exit(1)
+		}
+
+		if errArchiveFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting archive flag: %v\n", errArchiveFlag)
+			os.Exit(1)
+		}
+
+		if errArchiveDirFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting archive-dir flag: %v\n", errArchiveDirFlag)
+			os.Exit(1)
+		}
+
+		if errWorkersFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting workers flag: %v\n", errWorkersFlag)
+			os.Exit(1)
+		}
+
+		if errTrashFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting trash flag: %v\n", errTrashFlag)
+			os.Exit(1)
+		}
+
+		// An archive directory implies archiving
+		archiveFlag = archiveFlag || archiveDirFlag != ""
+		if archiveFlag && trashFlag {
+			fmt.Fprintln(os.Stderr, "Use either --archive or --trash")
+			os.Exit(1)
+		}
+		action := "deleted"
+		if archiveFlag {
+			action = "archived"
+		} else if trashFlag {
+			action = "moved to the trash"
+		}
+
+		fmt.Printf("Scanning %s...\n", ctx.Path)
+		modules, _, err := runScan(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error scanning: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Apply the filters. The git status is read again, the cached one may be a day old.
+		var targets []types.ScannedNodeModule
+		var totalSize int64
+		unsaved := make(map[string]string)
+		for _, module := range modules {
+			if reproducibleOnlyFlag && module.Reproducibility != types.ReproducibilityReproducible {
+				fmt.Printf("Skipping %s (%s)\n", module.Path, module.Reproducibility)
+				continue
+			}
+			if !includeUnsavedFlag {
+				dir := filepath.Dir(module.Path)
+				if _, ok := unsaved[dir]; !ok {
+					unsaved[dir] = clean.UnsavedWork(dir)
+				}
+				if unsaved[dir] != "" {
+					fmt.Printf("Skipping %s (%s)\n", module.Path, unsaved[dir])
+					continue
+				}
+			}
+			targets = append(targets, module)
+			totalSize += module.Size
+		}
+
+		if len(targets) == 0 {
+			fmt.Println("Nothing to clean")
+			return
+		}
+
+		for _, module := range targets {
+			fmt.Printf("  %s  %s  %s\n", module.Path, utils.FormatSize(module.Size), module.Reproducibility)
+		}
+
+		if dryRunFlag {
+			fmt.Printf("Dry run: %d directories (%s) would be %s\n", len(targets), utils.FormatSize(totalSize), action)
+			return
+		}
+
+		verb := "Delete"
+		if archiveFlag {
+			verb = "Archive and delete"
+		} else if trashFlag {
+			verb = "Move to the trash"
+		}
+		if !yesFlag {
+			question := fmt.Sprintf("%s %d directories (%s)?", verb, len(targets), utils.FormatSize(totalSize))
+			ask := confirm
+			if loadConfig().NeedsTypedYes(totalSize) {
+				ask = confirmTyped
+			}
+			if !ask(question) {
+				fmt.Println("Aborted")
+				return
+			}
+		}
+
+		var reclaimed int64
+		failed := 0
+		if archiveFlag {
+			for _, module := range targets {
+				archive, err := clean.ArchiveNodeModule(module.Path, ctx.Roots(), archiveDirFlag)
+				if err != nil {
+					utils.Log("Error archiving node_module: %v\n", err)
+					fmt.Fprintf(os.Stderr, "Failed %s: %v\n", module.Path, err)
+					failed++
+					continue
+				}
+				fmt.Printf("Archived %s (%s) to %s\n", module.Path, utils.FormatSize(module.Size), archive)
+				reclaimed += module.Size
+			}
+		} else if trashFlag {
+			for _, module := range targets {
+				dest, err := clean.TrashNodeModule(module.Path, ctx.Roots())
+				if err != nil {
+					utils.Log("Error moving node_module to the trash: %v\n", err)
+					fmt.Fprintf(os.Stderr, "Failed %s: %v\n", module.Path, err)
+					failed++
+					continue
+				}
+				fmt.Printf("Moved %s (%s) to %s\n", module.Path, utils.FormatSize(module.Size), dest)
+				reclaimed += module.Size
+			}
+		} else {
+			reclaimed, failed = batchDelete(targets, ctx.Roots(), workersFlag)
+		}
+
+		fmt.Printf("Reclaimed %s, %d failed\n", utils.FormatSize(reclaimed), failed)
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// batchDelete removes the targets with the batch engine and shows their progress.
+// On a terminal a bar per directory and an overall bar are redrawn in place,
+// otherwise only the outcome of each directory is printed.
+func batchDelete(targets []types.ScannedNodeModule, roots []string, workers int) (int64, int) {
+	paths := make([]string, 0, len(targets))
+	sizes := make(map[string]int64, len(targets))
+	var total int64
+	for _, module := range targets {
+		paths = append(paths, module.Path)
+		sizes[module.Path] = module.Size
+		total += module.Size
+	}
+
+	progress := make(chan clean.BatchProgress, 100)
+	go func() {
+		clean.BatchClean(paths, roots, workers, progress)
+		close(progress)
+	}()
+
+	info, err := os.Stdout.Stat()
+	tty := err == nil && info.Mode()&os.ModeCharDevice != 0
+
+	active := make(map[string]clean.BatchProgress)
+	var reclaimed, finishedBytes int64
+	failed, drawn := 0, 0
+	for p := range progress {
+		if tty && drawn > 0 {
+			fmt.Printf("\033[%dA\033[J", drawn) // Move up over the bars and clear them
+			drawn = 0
+		}
+
+		if !p.Done {
+			active[p.Path] = p
+		} else {
+			delete(active, p.Path)
+			finishedBytes += p.Bytes
+			if p.Err != nil {
+				utils.Log("Error deleting node_module: %v\n", p.Err)
+				fmt.Fprintf(os.Stderr, "Failed %s: %v\n", p.Path, p.Err)
+				failed++
+			} else {
+				fmt.Printf("Deleted %s (%s, %d files)\n", p.Path, utils.FormatSize(p.Bytes), p.Files)
+				reclaimed += p.Bytes
+			}
+		}
+
+		if !tty {
+			continue
+		}
+		current := finishedBytes
+		for _, path := range paths {
+			a, ok := active[path]
+			if !ok {
+				continue
+			}
+			current += a.Bytes
+			fmt.Printf("  %s %3d%%  %s\n", utils.ProgressBar(a.Bytes, sizes[path], 20), utils.Percent(a.Bytes, sizes[path]), path)
+			drawn++
+		}
+		fmt.Printf("  %s %3d%%  %s of %s\n", utils.ProgressBar(current, total, 20), utils.Percent(current, total), utils.FormatSize(current), utils.FormatSize(total))
+		drawn++
+	}
+
+	return reclaimed, failed
+}
+
+// runScan runs scan.NodeScan without the TUI, progress messages are discarded.
+func runScan(ctx types.ScanContext) ([]types.ScannedNodeModule, types.ScanInfo, error) {
+	ch := make(chan string, 1000)
+	go func() {
+		for range ch {
+		}
+	}()
+	return scan.NodeScan(ctx, ch)
+}
+
+// confirm asks a yes/no question on stdin, anything but "y" or "yes" is a no.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// confirmTyped asks a question that only "yes" typed out answers, for selections above the configured size.
+func confirmTyped(question string) bool {
+	fmt.Printf("%s Type yes to confirm: ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	return strings.ToLower(strings.TrimSpace(answer)) == "yes"
+}
+
+func init() {
+	rootCmd.AddCommand(cleanCmd)
+
+	addScanFlags(cleanCmd)
+	cleanCmd.Flags().Bool("reproducible-only", false, "Only delete node_modules that have a lockfile and can be reinstalled exactly")
+	cleanCmd.Flags().Bool("include-unsaved", false, "Also delete node_modules of projects with uncommitted or unpushed git work")
+	cleanCmd.Flags().Bool("dry-run", false, "Show what would be deleted without deleting anything")
+	cleanCmd.Flags().Bool("yes", false, "Do not ask for confirmation")
+	cleanCmd.Flags().Bool("archive", false, "Write each node_modules to a .tar.gz next to its project before deleting it, see sweepy restore")
+	cleanCmd.Flags().Bool("trash", false, "Move each node_modules to the trash instead of deleting it")
+	cleanCmd.Flags().Int("workers", clean.DefaultWorkers, "Number of directories deleted at the same time")
+	cleanCmd.Flags().String("archive-dir", "", "Write the archives to this directory instead of next to the projects, implies --archive")
+}
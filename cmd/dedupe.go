@@ -0,0 +1,110 @@
+/*
+Copyright © 2025 Neil Patrick Villanueva npdvillanueva@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/drxc00/sweepy/internal/clean"
+	"github.com/drxc00/sweepy/utils"
+	"github.com/spf13/cobra"
+)
+
+// dedupeCmd replaces identical dependency files with hard links
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe [directory]",
+	Short: "Replace identical files across node_modules with hard links",
+	Long: `Scans the directory and looks for byte-identical files in the node_modules directories found.
+Duplicates on the same filesystem are replaced with hard links to a single copy, keeping every project working.
+Linked files share their content: a package edited in place in one project changes in all of them.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getScanContext(cmd, args)
+
+		dryRunFlag, errDryRunFlag := cmd.Flags().GetBool("dry-run")
+		yesFlag, errYesFlag := cmd.Flags().GetBool("yes")
+
+		if errDryRunFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting dry-run flag: %v\n", errDryRunFlag)
+			os.Exit(1)
+		}
+
+		if errYesFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting yes flag: %v\n", errYesFlag)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Scanning %s...\n", ctx.Path)
+		modules, _, err := runScan(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error scanning: %v\n", err)
+			os.Exit(1)
+		}
+
+		var dirs []string
+		for _, module := range modules {
+			if !module.IsSymlink {
+				dirs = append(dirs, module.Path)
+			}
+		}
+
+		fmt.Printf("Looking for identical files in %d node_modules directories...\n", len(dirs))
+		sets, err := clean.FindIdenticalFiles(dirs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error looking for identical files: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(sets) == 0 {
+			fmt.Println("No identical files found")
+			return
+		}
+
+		var savable int64
+		duplicates := 0
+		for _, set := range sets {
+			savable += set.Saved()
+			duplicates += len(set.Paths) - 1
+		}
+		fmt.Printf("Found %d duplicate files in %d sets, linking them would save %s\n", duplicates, len(sets), utils.FormatSize(savable))
+
+		if dryRunFlag {
+			for _, set := range sets {
+				fmt.Printf("  %s  %d copies of %s\n", utils.FormatSize(set.Saved()), len(set.Paths), set.Paths[0])
+			}
+			fmt.Println("Dry run: nothing was linked")
+			return
+		}
+
+		if !yesFlag && !confirm(fmt.Sprintf("Replace %d files with hard links?", duplicates)) {
+			fmt.Println("Aborted")
+			return
+		}
+
+		var saved int64
+		failed := 0
+		for _, set := range sets {
+			n, err := clean.LinkIdenticalFiles(set)
+			saved += n
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed %v\n", err)
+				failed++
+			}
+		}
+
+		fmt.Printf("Saved %s, %d sets failed\n", utils.FormatSize(saved), failed)
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dedupeCmd)
+
+	addScanFlags(dedupeCmd)
+	dedupeCmd.Flags().Bool("dry-run", false, "Show what would be linked without changing anything")
+	dedupeCmd.Flags().Bool("yes", false, "Do not ask for confirmation")
+}
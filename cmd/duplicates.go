@@ -0,0 +1,115 @@
+/*
+Copyright © 2025 Neil Patrick Villanueva npdvillanueva@gmail.com
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/drxc00/sweepy/internal/scan"
+	"github.com/drxc00/sweepy/types"
+	"github.com/drxc00/sweepy/utils"
+	"github.com/spf13/cobra"
+)
+
+// duplicatesCmd reports the packages installed in many projects
+var duplicatesCmd = &cobra.Command{
+	Use:   "duplicates [directory]",
+	Short: "Report dependencies installed in more than one project",
+	Long: `Scans the directory and indexes the packages installed in every node_modules by name and version.
+Reports the packages duplicated the most, the bytes they waste and the projects that would benefit from a shared store such as pnpm.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getScanContext(cmd, args)
+
+		topFlag, errTopFlag := cmd.Flags().GetInt("top")
+		jsonFlag, errJsonFlag := cmd.Flags().GetBool("json")
+
+		if errTopFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting top flag: %v\n", errTopFlag)
+			os.Exit(1)
+		}
+
+		if errJsonFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting json flag: %v\n", errJsonFlag)
+			os.Exit(1)
+		}
+
+		if !jsonFlag {
+			fmt.Printf("Scanning %s...\n", ctx.Path)
+		}
+		modules, _, err := runScan(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error scanning: %v\n", err)
+			os.Exit(1)
+		}
+
+		duplicates := scan.FindDuplicates(modules)
+		candidates := scan.SharedStoreCandidates(modules, duplicates)
+
+		if jsonFlag {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			report := struct {
+				Duplicates  []types.DuplicatePackage     `json:"duplicates"`
+				SharedStore []types.SharedStoreCandidate `json:"sharedStoreCandidates"`
+			}{duplicates, candidates}
+			if err := encoder.Encode(report); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing JSON: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		printDuplicates(duplicates, candidates, topFlag)
+	},
+}
+
+// printDuplicates prints the top duplicated packages and shared store candidates.
+func printDuplicates(duplicates []types.DuplicatePackage, candidates []types.SharedStoreCandidate, top int) {
+	if len(duplicates) == 0 {
+		fmt.Println("No package is installed in more than one project")
+		return
+	}
+
+	var wasted int64
+	for _, d := range duplicates {
+		wasted += d.Wasted
+	}
+
+	shown := duplicates
+	if top > 0 && len(shown) > top {
+		shown = shown[:top]
+	}
+	fmt.Printf("\nMost duplicated packages (%d of %d):\n", len(shown), len(duplicates))
+	for _, d := range shown {
+		fmt.Printf("  %-40s %4d copies  %10s each  %10s wasted\n",
+			d.Name+"@"+d.Version, len(d.Projects), utils.FormatSize(d.Size), utils.FormatSize(d.Wasted))
+	}
+	fmt.Printf("\n%d duplicated packages waste %s\n", len(duplicates), utils.FormatSize(wasted))
+
+	if len(candidates) == 0 {
+		return
+	}
+	if top > 0 && len(candidates) > top {
+		candidates = candidates[:top]
+	}
+	fmt.Println("\nProjects that would benefit from a shared store (pnpm):")
+	for _, c := range candidates {
+		manager := c.PackageManager
+		if manager == "" {
+			manager = "unknown"
+		}
+		fmt.Printf("  %s (%s): %d duplicated packages, %s\n", c.Path, manager, c.Duplicated, utils.FormatSize(c.Size))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(duplicatesCmd)
+
+	addScanFlags(duplicatesCmd)
+	duplicatesCmd.Flags().Int("top", 20, "Number of packages and projects to list, 0 lists all of them")
+	duplicatesCmd.Flags().Bool("json", false, "Print the full report as JSON")
+}
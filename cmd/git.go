@@ -0,0 +1,85 @@
+/*
+Copyright © 2025 Neil Patrick Villanueva npdvillanueva@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/drxc00/sweepy/cmd/tui"
+	"github.com/drxc00/sweepy/internal/clean"
+	"github.com/drxc00/sweepy/internal/scan"
+	"github.com/drxc00/sweepy/types"
+	"github.com/spf13/cobra"
+)
+
+// gitCmd finds local branches that can be cleaned up
+var gitCmd = &cobra.Command{
+	Use:   "git [directory]",
+	Short: "Clean up merged and stale local git branches",
+	Long: `Scans the directory for git repositories and lists local branches that are already merged into the default branch,
+or whose last commit is older than the staleness. Deleted branches are recorded in .git/sweepy-undo.log and can be restored with --undo.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		stalenessFlag, errStalenessFlag := cmd.Flags().GetString("staleness")
+		dryRunFlag, errDryRunFlag := cmd.Flags().GetBool("dry-run")
+		undoFlag, errUndoFlag := cmd.Flags().GetBool("undo")
+
+		if errStalenessFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting staleness flag: %v\n", errStalenessFlag)
+			os.Exit(1)
+		}
+
+		if errDryRunFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting dry-run flag: %v\n", errDryRunFlag)
+			os.Exit(1)
+		}
+
+		if errUndoFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting undo flag: %v\n", errUndoFlag)
+			os.Exit(1)
+		}
+
+		scanPath := getScanPath(args)
+
+		if undoFlag {
+			undoBranchDeletes(scanPath)
+			return
+		}
+
+		ctx := types.NewScanContext(scanPath, stalenessFlag, false, false)
+		tui.ScanGit(ctx, loadConfig(), dryRunFlag)
+	},
+}
+
+// undoBranchDeletes restores the branches sweepy deleted in every repository under scanPath.
+func undoBranchDeletes(scanPath string) {
+	repos, err := scan.FindGitRepos(scanPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error scanning for git repositories: %v\n", err)
+		os.Exit(1)
+	}
+
+	total := 0
+	for _, repo := range repos {
+		restored, err := clean.RestoreBranches(repo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error restoring branches in %s: %v\n", repo, err)
+			continue
+		}
+		for _, name := range restored {
+			fmt.Printf("Restored %s in %s\n", name, repo)
+		}
+		total += len(restored)
+	}
+	fmt.Printf("Restored %d branches\n", total)
+}
+
+func init() {
+	rootCmd.AddCommand(gitCmd)
+
+	gitCmd.Flags().StringP("staleness", "s", "90", "Branches whose last commit is older than this many days are listed. 0 only lists merged branches.")
+	gitCmd.Flags().Bool("dry-run", false, "Show which branches would be deleted without deleting them")
+	gitCmd.Flags().Bool("undo", false, "Restore the branches deleted by sweepy")
+}
@@ -0,0 +1,171 @@
+/*
+Copyright © 2025 Neil Patrick Villanueva npdvillanueva@gmail.com
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/drxc00/sweepy/internal/clean"
+	"github.com/drxc00/sweepy/types"
+	"github.com/drxc00/sweepy/utils"
+	"github.com/spf13/cobra"
+)
+
+// historyCmd lists what sweepy removed
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List past removals and the space they reclaimed",
+	Long: `Reads the journal, where every attempt to remove a node_modules is recorded, and lists the attempts matching
+the filters with the total space reclaimed. Use --by to get totals per period.
+The journal is sweepy/journal.jsonl in the user config directory, or the file named by SWEEPY_JOURNAL.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		sinceFlag, errSinceFlag := cmd.Flags().GetString("since")
+		untilFlag, errUntilFlag := cmd.Flags().GetString("until")
+		modeFlag, errModeFlag := cmd.Flags().GetString("mode")
+		resultFlag, errResultFlag := cmd.Flags().GetString("result")
+		userFlag, errUserFlag := cmd.Flags().GetString("user")
+		pathFlag, errPathFlag := cmd.Flags().GetString("path")
+		byFlag, errByFlag := cmd.Flags().GetString("by")
+		jsonFlag, errJsonFlag := cmd.Flags().GetBool("json")
+
+		if errSinceFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting since flag: %v\n", errSinceFlag)
+			os.Exit(1)
+		}
+
+		if errUntilFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting until flag: %v\n", errUntilFlag)
+			os.Exit(1)
+		}
+
+		if errModeFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting mode flag: %v\n", errModeFlag)
+			os.Exit(1)
+		}
+
+		if errResultFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting result flag: %v\n", errResultFlag)
+			os.Exit(1)
+		}
+
+		if errUserFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting user flag: %v\n", errUserFlag)
+			os.Exit(1)
+		}
+
+		if errPathFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting path flag: %v\n", errPathFlag)
+			os.Exit(1)
+		}
+
+		if errByFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting by flag: %v\n", errByFlag)
+			os.Exit(1)
+		}
+
+		if errJsonFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting json flag: %v\n", errJsonFlag)
+			os.Exit(1)
+		}
+
+		switch byFlag {
+		case "", "day", "month", "quarter", "year":
+		default:
+			fmt.Fprintf(os.Stderr, "Invalid by %q: must be day, month, quarter or year\n", byFlag)
+			os.Exit(1)
+		}
+
+		filter := clean.JournalFilter{Mode: modeFlag, Result: resultFlag, User: userFlag, Path: pathFlag}
+		var err error
+		if filter.Since, err = parseHistoryTime(sinceFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid since: %v\n", err)
+			os.Exit(1)
+		}
+		if filter.Until, err = parseHistoryTime(untilFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid until: %v\n", err)
+			os.Exit(1)
+		}
+
+		entries, err := clean.ReadJournal()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", clean.JournalPath(), err)
+			os.Exit(1)
+		}
+
+		var matched []types.JournalEntry
+		for _, entry := range entries {
+			if filter.Match(entry) {
+				matched = append(matched, entry)
+			}
+		}
+
+		if jsonFlag {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			var report any = matched
+			if byFlag != "" {
+				report = clean.TotalsBy(matched, byFlag)
+			}
+			if err := encoder.Encode(report); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing JSON: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if len(matched) == 0 {
+			fmt.Println("No removals recorded")
+			return
+		}
+
+		if byFlag == "" {
+			for _, entry := range matched {
+				result := entry.Result
+				if entry.Error != "" {
+					result += ": " + entry.Error
+				}
+				fmt.Printf("%s  %-7s %10s  %s  %s (%s)\n",
+					entry.Time.Local().Format("2006-01-02 15:04"), entry.Mode, utils.FormatSize(entry.Bytes), entry.Path, entry.User, result)
+			}
+			fmt.Println()
+		}
+
+		for _, total := range clean.TotalsBy(matched, byFlag) {
+			fmt.Printf("%-10s %4d removals, %d failed, %s reclaimed\n",
+				total.Period, total.Attempts, total.Failed, utils.FormatSize(total.Reclaimed))
+		}
+	},
+}
+
+// parseHistoryTime accepts a date (2006-01-02) or a number of days ago.
+func parseHistoryTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	days, err := utils.ParseStalenessFlagValue(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a date (YYYY-MM-DD) nor a number of days", value)
+	}
+	return time.Now().AddDate(0, 0, -int(days)), nil
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().String("since", "", "Only removals on or after this date (YYYY-MM-DD) or number of days ago")
+	historyCmd.Flags().String("until", "", "Only removals before this date (YYYY-MM-DD) or number of days ago")
+	historyCmd.Flags().String("mode", "", "Only removals done this way: rm, trash or archive")
+	historyCmd.Flags().String("result", "", "Only removals with this result: ok or failed")
+	historyCmd.Flags().String("user", "", "Only removals by this user")
+	historyCmd.Flags().String("path", "", "Only removals of paths containing this text")
+	historyCmd.Flags().String("by", "", "Total per day, month, quarter or year instead of listing every removal")
+	historyCmd.Flags().Bool("json", false, "Print the removals, or the totals with --by, as JSON")
+}
@@ -0,0 +1,83 @@
+/*
+Copyright © 2025 Neil Patrick Villanueva npdvillanueva@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/drxc00/sweepy/internal/clean"
+	"github.com/drxc00/sweepy/internal/project"
+	"github.com/spf13/cobra"
+)
+
+// reinstallCmd brings back the dependencies of a cleaned project
+var reinstallCmd = &cobra.Command{
+	Use:   "reinstall <project>",
+	Short: "Print or run the install command of a cleaned project",
+	Long: `Detects the package manager of the project and prints the command that reinstalls its node_modules
+exactly from the lockfile (npm ci, pnpm install --frozen-lockfile, ...). With --run the command is executed.
+Warns when the lockfile changed since sweepy removed node_modules, as recorded in the journal (see sweepy history).`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runFlag, errRunFlag := cmd.Flags().GetBool("run")
+		if errRunFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting run flag: %v\n", errRunFlag)
+			os.Exit(1)
+		}
+
+		// Accept both the project and its node_modules
+		dir, err := filepath.Abs(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error resolving %s: %v\n", args[0], err)
+			os.Exit(1)
+		}
+		if filepath.Base(dir) == "node_modules" {
+			dir = filepath.Dir(dir)
+		}
+
+		if info, err := os.Stat(filepath.Join(dir, "package.json")); err != nil || info.IsDir() {
+			fmt.Fprintf(os.Stderr, "%s has no package.json\n", dir)
+			os.Exit(1)
+		}
+
+		entry, found, err := clean.LastRemoval(filepath.Join(dir, "node_modules"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", clean.JournalPath(), err)
+		}
+		switch {
+		case !found:
+			fmt.Printf("No removal of %s recorded in %s\n", filepath.Join(dir, "node_modules"), clean.JournalPath())
+		case clean.LockfileChanged(entry):
+			fmt.Printf("Warning: %s changed since node_modules was removed on %s, the install will not match the removed tree\n",
+				entry.Lockfile, entry.Time.Format("2006-01-02 15:04"))
+		default:
+			fmt.Printf("node_modules was removed on %s, the lockfile did not change since\n", entry.Time.Format("2006-01-02 15:04"))
+		}
+
+		installDir, command := project.InstallCommand(dir)
+		if !runFlag {
+			fmt.Printf("cd %q && %s\n", installDir, strings.Join(command, " "))
+			return
+		}
+
+		fmt.Printf("Running %s in %s\n", strings.Join(command, " "), installDir)
+		install := exec.Command(command[0], command[1:]...)
+		install.Dir = installDir
+		install.Stdin, install.Stdout, install.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := install.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running %s: %v\n", command[0], err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reinstallCmd)
+
+	reinstallCmd.Flags().Bool("run", false, "Run the install command instead of printing it")
+}
@@ -0,0 +1,63 @@
+/*
+Copyright © 2025 Neil Patrick Villanueva npdvillanueva@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/drxc00/sweepy/internal/clean"
+	"github.com/spf13/cobra"
+)
+
+// restoreCmd unpacks archives written by sweepy clean --archive
+var restoreCmd = &cobra.Command{
+	Use:   "restore <archive>...",
+	Short: "Unpack node_modules archives back in place",
+	Long: `Unpacks archives written by sweepy clean --archive to the path they were taken from.
+Nothing is overwritten: an archive whose node_modules exists again is skipped. The archive is deleted once restored unless --keep is set.
+An archive is only unpacked next to itself, or under a --root for archives written with --archive-dir.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		keepFlag, errKeepFlag := cmd.Flags().GetBool("keep")
+		if errKeepFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting keep flag: %v\n", errKeepFlag)
+			os.Exit(1)
+		}
+
+		rootFlag, errRootFlag := cmd.Flags().GetStringSlice("root")
+		if errRootFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting root flag: %v\n", errRootFlag)
+			os.Exit(1)
+		}
+
+		failed := 0
+		for _, archive := range args {
+			target, err := clean.RestoreArchive(archive, rootFlag)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed %s: %v\n", archive, err)
+				failed++
+				continue
+			}
+			fmt.Printf("Restored %s\n", target)
+
+			if !keepFlag {
+				if err := os.Remove(archive); err != nil {
+					fmt.Fprintf(os.Stderr, "Error removing %s: %v\n", archive, err)
+				}
+			}
+		}
+
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	restoreCmd.Flags().Bool("keep", false, "Keep the archive after restoring it")
+	restoreCmd.Flags().StringSlice("root", nil, "Directory the archives may be restored under, besides next to the archive (repeatable)")
+}
@@ -4,11 +4,13 @@ Copyright © 2025 Neil Patrick Villanueva npdvillanueva@gmail.com
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/drxc00/sweepy/cmd/tui"
+	"github.com/drxc00/sweepy/internal/config"
 	"github.com/drxc00/sweepy/types"
 	"github.com/spf13/cobra"
 )
@@ -19,55 +21,142 @@ var rootCmd = &cobra.Command{
 	Short:            "Your Terminal Janitor for Cleaning Up Your Development Environment",
 	Long:             `Sweepy is a lightweight, dependency-free CLI tool that helps you keep your development environment clean and clutter-free.`,
 	TraverseChildren: true,
+	Args:             cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		// Vars
-		var scanPath string
+		ctx := getScanContext(cmd, args)
 
-		// Flags
-		stalenessFlag, errStalenessFlag := cmd.Flags().GetString("staleness")
-		noCacheFlag, errNoCacheFlag := cmd.Flags().GetBool("no-cache")
-		resetCacheFlag, errResetCacheFlag := cmd.Flags().GetBool("reset-cache")
+		jsonFlag, errJsonFlag := cmd.Flags().GetBool("json")
+		noConfirmFlag, errNoConfirmFlag := cmd.Flags().GetBool("no-confirm")
 
-		if errResetCacheFlag != nil {
-			fmt.Fprintf(os.Stderr, "Error getting reset-cache flag: %v\n", errResetCacheFlag)
+		if errJsonFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting json flag: %v\n", errJsonFlag)
 			os.Exit(1)
 		}
 
-		if errStalenessFlag != nil {
-			fmt.Fprintf(os.Stderr, "Error getting staleness flag: %v\n", errStalenessFlag)
+		if errNoConfirmFlag != nil {
+			fmt.Fprintf(os.Stderr, "Error getting no-confirm flag: %v\n", errNoConfirmFlag)
 			os.Exit(1)
 		}
 
-		if errNoCacheFlag != nil {
-			fmt.Fprintf(os.Stderr, "Error getting no-cache flag: %v\n", errNoCacheFlag)
-			os.Exit(1)
+		if jsonFlag {
+			printJSON(ctx)
+			return
 		}
 
-		// Check the args
-		if len(args) > 0 {
-			scanPath = args[0]
-		} else {
-			// Set the current directory as the default scan path
-			// If no arguments are provided.
-			currentDir, err := os.Getwd() // Get the current directory
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
-				os.Exit(1)
-			}
-
-			// Convert the current directory to a Windows path
-			scanPath = filepath.ToSlash(currentDir)
-		}
+		tui.ScanNode(ctx, loadConfig(), noConfirmFlag)
 
-		ctx := types.NewScanContext(scanPath,
-			stalenessFlag,
-			noCacheFlag,
-			resetCacheFlag,
-		)
+	},
+}
 
-		tui.ScanNode(ctx)
+// addScanFlags registers the flags that control a node_modules scan.
+// They are shared by every command that runs scan.NodeScan.
+func addScanFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("staleness", "s", "0", "The staleness of the node_modules directory. Accepts input in days. If no units are specified, it defaults to days.")
+	cmd.Flags().String("staleness-source", types.StalenessSourceMtime, "What the staleness is based on: mtime (newest file), git (last commit) or max (most recent of both).")
+	cmd.Flags().BoolP("no-cache", "c", false, "Disable caching")
+	cmd.Flags().BoolP("reset-cache", "r", false, "Reset the cache")
+	cmd.Flags().BoolP("follow-symlinks", "L", false, "Follow symbolic links while scanning. Link loops and directories reached twice are skipped.")
+}
 
-	},
+// getScanContext builds the scan context from the flags registered by addScanFlags.
+func getScanContext(cmd *cobra.Command, args []string) types.ScanContext {
+	// Vars
+	var scanPath string
+
+	// Flags
+	stalenessFlag, errStalenessFlag := cmd.Flags().GetString("staleness")
+	stalenessSourceFlag, errStalenessSourceFlag := cmd.Flags().GetString("staleness-source")
+	noCacheFlag, errNoCacheFlag := cmd.Flags().GetBool("no-cache")
+	resetCacheFlag, errResetCacheFlag := cmd.Flags().GetBool("reset-cache")
+	followSymlinksFlag, errFollowSymlinksFlag := cmd.Flags().GetBool("follow-symlinks")
+
+	if errResetCacheFlag != nil {
+		fmt.Fprintf(os.Stderr, "Error getting reset-cache flag: %v\n", errResetCacheFlag)
+		os.Exit(1)
+	}
+
+	if errStalenessFlag != nil {
+		fmt.Fprintf(os.Stderr, "Error getting staleness flag: %v\n", errStalenessFlag)
+		os.Exit(1)
+	}
+
+	if errStalenessSourceFlag != nil {
+		fmt.Fprintf(os.Stderr, "Error getting staleness-source flag: %v\n", errStalenessSourceFlag)
+		os.Exit(1)
+	}
+
+	switch stalenessSourceFlag {
+	case types.StalenessSourceMtime, types.StalenessSourceGit, types.StalenessSourceMax:
+	default:
+		fmt.Fprintf(os.Stderr, "Invalid staleness-source %q: must be mtime, git or max\n", stalenessSourceFlag)
+		os.Exit(1)
+	}
+
+	if errNoCacheFlag != nil {
+		fmt.Fprintf(os.Stderr, "Error getting no-cache flag: %v\n", errNoCacheFlag)
+		os.Exit(1)
+	}
+
+	if errFollowSymlinksFlag != nil {
+		fmt.Fprintf(os.Stderr, "Error getting follow-symlinks flag: %v\n", errFollowSymlinksFlag)
+		os.Exit(1)
+	}
+
+	scanPath = getScanPath(args)
+
+	ctx := types.NewScanContext(scanPath,
+		stalenessFlag,
+		noCacheFlag,
+		resetCacheFlag,
+	)
+	ctx.FollowSymlinks = followSymlinksFlag
+	ctx.StalenessSource = stalenessSourceFlag
+
+	return ctx
+}
+
+// loadConfig reads sweepy.config.json, a broken file stops sweepy rather than being ignored.
+func loadConfig() config.Config {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// printJSON scans without the TUI and writes the results to stdout.
+func printJSON(ctx types.ScanContext) {
+	modules, _, err := runScan(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error scanning: %v\n", err)
+		os.Exit(1)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false) // Keep engine ranges like ">=18" readable
+	if err := encoder.Encode(modules); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing JSON: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// getScanPath returns the directory passed as argument,
+// or the current directory if no arguments are provided.
+func getScanPath(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+
+	currentDir, err := os.Getwd() // Get the current directory
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Convert the current directory to a Windows path
+	return filepath.ToSlash(currentDir)
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -82,9 +171,9 @@ func Execute() {
 func init() {
 
 	// Flags to scanCmd
-	rootCmd.Flags().StringP("staleness", "s", "0", "The staleness of the node_modules directory. Accepts input in days. If no units are specified, it defaults to days.")
-	rootCmd.Flags().BoolP("no-cache", "c", false, "Disable caching")
-	rootCmd.Flags().BoolP("reset-cache", "r", false, "Reset the cache")
+	addScanFlags(rootCmd)
+	rootCmd.Flags().Bool("json", false, "Print the scan results as JSON instead of starting the interactive UI")
+	rootCmd.Flags().Bool("no-confirm", false, "Delete, trash or archive the selection as soon as the key is pressed, without the confirmation dialog unless a project has unsaved git work")
 	rootCmd.Flags().BoolP("system", "y", false, "Scan the entire system for node_modules directories. Determines all drives and scans them.")
 
 }
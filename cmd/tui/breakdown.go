@@ -0,0 +1,166 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/drxc00/sweepy/types"
+	"github.com/drxc00/sweepy/utils"
+)
+
+// breakdownView lists the packages inside one node_modules directory.
+type breakdownView struct {
+	module   types.ScannedNodeModule
+	loading  bool
+	packages []types.PackageSize
+	table    table.Model
+	err      error
+}
+
+type breakdownMsg struct {
+	path     string
+	packages []types.PackageSize
+	err      error
+}
+
+// openBreakdown shows the breakdown of module and starts measuring its packages.
+func (m model) openBreakdown(module types.ScannedNodeModule) (tea.Model, tea.Cmd) {
+	m.breakdown = &breakdownView{module: module, loading: true}
+	m.notice = ""
+	return m, func() tea.Msg {
+		return LoadBreakdown(module.Path)
+	}
+}
+
+// updateBreakdown handles the keys while the breakdown is shown.
+func (m model) updateBreakdown(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "ctrl+c", key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Back, m.keys.Collapse):
+		m.breakdown = nil
+	case key.Matches(msg, m.keys.Up):
+		m.breakdown.table.MoveUp(1)
+	case key.Matches(msg, m.keys.Down):
+		m.breakdown.table.MoveDown(1)
+	}
+	return m, nil
+}
+
+// setBreakdown fills the breakdown table once the packages are measured.
+func (m model) setBreakdown(msg breakdownMsg) model {
+	// The user went back, or opened another one, before this one finished
+	if m.breakdown == nil || m.breakdown.module.Path != msg.path {
+		return m
+	}
+
+	m.breakdown.loading = false
+	m.breakdown.packages = msg.packages
+	m.breakdown.err = msg.err
+
+	columns := fitColumns(breakdownLayout, m.width-6)
+
+	var rows []table.Row
+	for _, pkg := range msg.packages {
+		size := utils.FormatSize(pkg.Size)
+		if pkg.IsSymlink {
+			size = "symlink"
+		}
+		nested, nestedSize := "", ""
+		if pkg.Nested > 0 {
+			nested = fmt.Sprintf("%d", pkg.Nested)
+			nestedSize = utils.FormatSize(pkg.NestedSize)
+		}
+		rows = append(rows, table.Row{pkg.Name, pkg.Version, size, nested, nestedSize})
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(true),
+	)
+	t.SetStyles(tableStyles())
+	t.SetWidth(m.width - 6) // The height is set by layout
+	m.breakdown.table = t
+	return m
+}
+
+// breakdownContent renders the breakdown in place of the node_modules table.
+func (m model) breakdownContent() string {
+	bd := m.breakdown
+	if !bd.loading && bd.err == nil {
+		tableBorder := lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(colorBorder).
+			Padding(1, 1)
+		return m.breakdownHeaderView() + tableBorder.Render(bd.table.View()) + m.breakdownFooterView()
+	}
+
+	var b strings.Builder
+
+	b.WriteString("\n")
+	b.WriteString(titleStyle.Render("📦 " + m.projectName(bd.module) + " 📦"))
+	b.WriteString("\n\n")
+
+	if bd.loading {
+		b.WriteString(scanningStatusStyle.Render(fmt.Sprintf(
+			"%s %s",
+			m.spinner.View(),
+			scanningLabelStyle.Render("Measuring packages in "+bd.module.Path+"..."),
+		)))
+		b.WriteString("\n\n")
+		b.WriteString(loadingFooterStyle.Render(m.help.ShortHelpView(m.keys.breakdownHelp())))
+		return b.String()
+	}
+
+	b.WriteString(errorStyle.Render("Error: " + bd.err.Error()))
+	b.WriteString("\n\n")
+	b.WriteString(loadingFooterStyle.Render(m.help.ShortHelpView(m.keys.breakdownHelp())))
+	return b.String()
+}
+
+// breakdownHeaderView renders the title and the totals above the packages.
+func (m model) breakdownHeaderView() string {
+	bd := m.breakdown
+	var b strings.Builder
+
+	if !m.compact() {
+		b.WriteString("\n")
+	}
+	b.WriteString(titleStyle.Render("📦 " + m.projectName(bd.module) + " 📦"))
+	b.WriteString("\n\n")
+
+	var total, nestedSize int64
+	nested := 0
+	for _, pkg := range bd.packages {
+		total += pkg.Size
+		nested += pkg.Nested
+		nestedSize += pkg.NestedSize
+	}
+
+	stats := fmt.Sprintf(
+		"%s %s\n%s %s\n%s %s\n",
+		statsLabelStyle.Render("Packages:"),
+		statsValueStyle.Render(fmt.Sprintf("%d in %s", len(bd.packages), bd.module.Path)),
+		statsLabelStyle.Render("Total Size:"),
+		statsValueStyle.Render(utils.FormatSize(total)),
+		statsLabelStyle.Render("Nested Copies:"),
+		statsValueStyle.Render(fmt.Sprintf("%d (%s)", nested, utils.FormatSize(nestedSize))),
+	)
+	b.WriteString(statsStyle.MaxWidth(m.width).Render(stats))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// breakdownFooterView renders the keys below the packages.
+func (m model) breakdownFooterView() string {
+	return "\n" + lipgloss.NewStyle().
+		Foreground(colorSecondary).
+		Align(lipgloss.Center).
+		Width(m.width-4).
+		Render(m.help.ShortHelpView(m.keys.breakdownHelp()))
+}
@@ -0,0 +1,231 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/drxc00/sweepy/internal/clean"
+	"github.com/drxc00/sweepy/types"
+	"github.com/drxc00/sweepy/utils"
+)
+
+// How many paths the confirmation lists before summarizing the rest
+const confirmMaxPaths = 8
+
+// confirmDialog asks before a bulk action touches anything on disk.
+type confirmDialog struct {
+	mode    string // One of the types.JournalMode* values
+	modules []types.ScannedNodeModule
+	size    int64
+	hidden  int // Selected rows the filter hides
+
+	// The git status is read in the background, the dialog cannot be answered until it is in
+	checking bool
+	unsaved  map[string]string // What is at risk by project directory, see clean.UnsavedWork
+	auto     bool              // --no-confirm: act as soon as the status shows nothing at risk
+
+	// Selection to put back when the dialog closes, set when a tree directory replaced it
+	prior map[string]bool
+
+	// Big selections need "yes" typed out, a single key is too easy to hit by accident
+	typeYes bool
+	input   string
+}
+
+// unsavedMsg carries the git status read for a dialog.
+type unsavedMsg struct {
+	dialog  *confirmDialog
+	unsaved map[string]string
+}
+
+// atRisk tells whether the project of module has unsaved work.
+func (d *confirmDialog) atRisk(module types.ScannedNodeModule) bool {
+	return d.unsaved[filepath.Dir(module.Path)] != ""
+}
+
+// unsavedCount is the number of selected rows whose project has unsaved work.
+func (d *confirmDialog) unsavedCount() int {
+	count := 0
+	for _, module := range d.modules {
+		if d.atRisk(module) {
+			count++
+		}
+	}
+	return count
+}
+
+// requestAction opens the confirmation for applying mode to the selection.
+// With --no-confirm it is applied without asking, unless a project has unsaved work
+// or the filter hides some of the selection.
+// The git status is read again in the background, it may have changed since the scan.
+func (m model) requestAction(mode string) (tea.Model, tea.Cmd) {
+	selected := m.selectedModules()
+	if len(selected) == 0 {
+		m.notice = "Nothing selected, use space to select rows."
+		return m, nil
+	}
+
+	dialog := &confirmDialog{mode: mode, modules: selected, size: m.selectedSize(), checking: true, auto: m.noConfirm}
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, module := range selected {
+		if dir := filepath.Dir(module.Path); !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+		if !m.matchesFilter(module) {
+			dialog.hidden++
+		}
+	}
+	dialog.typeYes = m.config.NeedsTypedYes(dialog.size)
+
+	m.confirm = dialog
+	m.notice = ""
+	return m, func() tea.Msg {
+		unsaved := make(map[string]string, len(dirs))
+		for _, dir := range dirs {
+			unsaved[dir] = clean.UnsavedWork(dir)
+		}
+		return unsavedMsg{dialog: dialog, unsaved: unsaved}
+	}
+}
+
+// setUnsaved fills the dialog with the git status once read.
+// A status read for a dialog that was closed since is dropped.
+func (m model) setUnsaved(msg unsavedMsg) (tea.Model, tea.Cmd) {
+	dialog := m.confirm
+	if dialog == nil || dialog != msg.dialog {
+		return m, nil
+	}
+	dialog.checking = false
+	dialog.unsaved = msg.unsaved
+	if dialog.auto && dialog.unsavedCount() == 0 && dialog.hidden == 0 {
+		return m.confirmAction(dialog)
+	}
+	return m, nil
+}
+
+// updateConfirm handles the keys while the confirmation is shown.
+// Nothing but an explicit answer closes it.
+func (m model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	dialog := m.confirm
+	key := msg.String()
+
+	if key == "esc" || (!dialog.typeYes && key == "n") {
+		m.confirm, m.notice = nil, "Cancelled."
+		m.restoreSelection(dialog.prior, nil)
+		return m, nil
+	}
+
+	if dialog.checking {
+		return m, nil
+	}
+
+	if !dialog.typeYes {
+		if key == "y" {
+			return m.confirmAction(dialog)
+		}
+		return m, nil
+	}
+
+	switch key {
+	case "enter":
+		if strings.ToLower(dialog.input) == "yes" {
+			return m.confirmAction(dialog)
+		}
+		dialog.input = ""
+	case "backspace":
+		if len(dialog.input) > 0 {
+			dialog.input = dialog.input[:len(dialog.input)-1]
+		}
+	default:
+		if len(key) == 1 && len(dialog.input) < 3 {
+			dialog.input += key
+		}
+	}
+	return m, nil
+}
+
+// confirmAction applies the confirmed action, then puts back the rest of an earlier selection.
+func (m model) confirmAction(dialog *confirmDialog) (tea.Model, tea.Cmd) {
+	next, cmd := m.runAction(dialog.mode)
+	nm := next.(model)
+	nm.restoreSelection(dialog.prior, dialog.modules)
+	return nm, cmd
+}
+
+// restoreSelection puts back the selection prior, but for the modules acted on.
+// A nil prior leaves the selection as it is.
+func (m *model) restoreSelection(prior map[string]bool, acted []types.ScannedNodeModule) {
+	if prior == nil {
+		return
+	}
+	m.selected = maps.Clone(prior)
+	for _, module := range acted {
+		delete(m.selected, module.Path)
+	}
+	m.refreshRows()
+}
+
+// confirmContent renders the confirmation in the middle of the screen.
+func (m model) confirmContent() string {
+	dialog := m.confirm
+	var b strings.Builder
+
+	b.WriteString(statsLabelStyle.Render(fmt.Sprintf("%s %d directories?", actionVerbs[dialog.mode], len(dialog.modules))))
+	b.WriteString("\n\n")
+
+	// A short terminal lists fewer paths, the rest of the dialog must fit
+	reserved := 14
+	if dialog.hidden > 0 {
+		reserved++
+	}
+	shown := dialog.modules
+	if limit := min(confirmMaxPaths, max(m.height-reserved, 1)); len(shown) > limit {
+		shown = shown[:limit]
+	}
+	for _, module := range shown {
+		line := fmt.Sprintf("%10s  %s", utils.FormatSize(module.Size), module.Path)
+		if dialog.atRisk(module) {
+			line = noticeStyle.UnsetPadding().Render(line + "  ⚠")
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	if more := len(dialog.modules) - len(shown); more > 0 {
+		b.WriteString(loadingPathStyle.Render(fmt.Sprintf("... and %d more", more)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf("%s %s\n", statsLabelStyle.Render("Action:"), statsValueStyle.Render(actionDescriptions[dialog.mode])))
+	b.WriteString(fmt.Sprintf("%s %s\n", statsLabelStyle.Render("Total:"), statsValueStyle.Render(utils.FormatSize(dialog.size))))
+	if dialog.checking {
+		b.WriteString(loadingPathStyle.Render("Checking git status…"))
+		b.WriteString("\n")
+	} else if unsaved := dialog.unsavedCount(); unsaved > 0 {
+		b.WriteString(noticeStyle.UnsetPadding().Render(fmt.Sprintf("⚠ %d of them have uncommitted changes, unpushed commits or a repository that cannot be read.", unsaved)))
+		b.WriteString("\n")
+	}
+	if dialog.hidden > 0 {
+		b.WriteString(noticeStyle.UnsetPadding().Render(fmt.Sprintf("⚠ %d of them are hidden by the filter.", dialog.hidden)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if dialog.checking {
+		b.WriteString(loadingPathStyle.Render("esc: cancel"))
+	} else if dialog.typeYes {
+		b.WriteString(fmt.Sprintf("Type yes and press enter to confirm: %s_\n", dialog.input))
+		b.WriteString(loadingPathStyle.Render("esc: cancel"))
+	} else {
+		b.WriteString(loadingPathStyle.Render("y: confirm • n/esc: cancel"))
+	}
+
+	box := fitBox(confirmBoxStyle, b.String(), m.width)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,230 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/drxc00/sweepy/types"
+	"github.com/drxc00/sweepy/utils"
+)
+
+// How long the cursor must rest on a row before its details are read.
+// Scrolling through the list does not start a walk of every directory passed.
+const detailRestDelay = 300 * time.Millisecond
+
+// Packages listed in the detail pane
+const detailMaxPackages = 5
+
+// Narrower terminals leave the whole width to the table
+const detailMinTerminalWidth = 100
+
+// detailEntry is the state of the details of one node_modules.
+type detailEntry struct {
+	loading bool
+	details types.ArtifactDetails
+	err     error
+}
+
+// detailRestMsg fires once the cursor stayed on path for detailRestDelay.
+type detailRestMsg struct {
+	path string
+}
+
+type detailsMsg struct {
+	path    string
+	details types.ArtifactDetails
+	err     error
+}
+
+// detailPaneWidth is the width taken by the detail pane, 0 when it is hidden.
+func (m model) detailPaneWidth() int {
+	if !m.showDetails || m.tree != nil || m.width < detailMinTerminalWidth {
+		return 0
+	}
+	return max(m.width/3, 40)
+}
+
+// listWidth is the width left to the node_modules table.
+func (m model) listWidth() int {
+	return m.width - m.detailPaneWidth()
+}
+
+// listColumns sizes the columns of the node_modules table to the width left to it.
+func (m model) listColumns() []table.Column {
+	return fitColumns(listLayout, m.listWidth()-6)
+}
+
+// resizeList fits the node_modules table to the width left to it.
+func (m *model) resizeList() {
+	m.table.SetColumns(m.listColumns())
+	m.table.SetWidth(m.listWidth() - 6)
+	m.updateSortHeader()
+	m.refreshRows()
+}
+
+// toggleDetails shows or hides the detail pane.
+func (m model) toggleDetails() (tea.Model, tea.Cmd) {
+	m.showDetails = !m.showDetails
+	if m.showDetails && m.width < detailMinTerminalWidth {
+		m.notice = fmt.Sprintf("The detail pane needs a terminal at least %d columns wide, it shows up once there is room.", detailMinTerminalWidth)
+	}
+	m.resizeList()
+	return m, nil
+}
+
+// cursorModule returns the module under the cursor, workspace headers have none.
+func (m model) cursorModule() (types.ScannedNodeModule, bool) {
+	row, ok := m.selectedRow()
+	if !ok || row.isHeader() {
+		return types.ScannedNodeModule{}, false
+	}
+	return m.modules[row.module], true
+}
+
+// watchDetails waits for the cursor to rest on a row whose details are not read yet.
+func (m model) watchDetails() tea.Cmd {
+	if m.detailPaneWidth() == 0 {
+		return nil
+	}
+	module, ok := m.cursorModule()
+	if !ok {
+		return nil
+	}
+	if _, ok := m.details[module.Path]; ok {
+		return nil
+	}
+	return tea.Tick(detailRestDelay, func(time.Time) tea.Msg {
+		return detailRestMsg{path: module.Path}
+	})
+}
+
+// startDetails reads the details once the cursor rested on the row.
+func (m model) startDetails(msg detailRestMsg) (tea.Model, tea.Cmd) {
+	module, ok := m.cursorModule()
+	if m.detailPaneWidth() == 0 || !ok || module.Path != msg.path {
+		return m, nil // The cursor moved on, or the pane was closed
+	}
+	if _, ok := m.details[msg.path]; ok {
+		return m, nil
+	}
+	m.details[msg.path] = &detailEntry{loading: true}
+	return m, func() tea.Msg {
+		return LoadDetails(msg.path)
+	}
+}
+
+// detailPane renders the details of the module under the cursor.
+func (m model) detailPane() string {
+	width := m.detailPaneWidth()
+	style := detailPaneStyle.Width(width - 4)
+	valueWidth := width - 8
+
+	module, ok := m.cursorModule()
+	if !ok {
+		return style.Render(loadingPathStyle.Render("Move to a node_modules to see its details."))
+	}
+
+	var b strings.Builder
+	line := func(label, value string) {
+		b.WriteString(statsLabelStyle.Render(label))
+		b.WriteString("\n")
+		b.WriteString(statsValueStyle.Width(valueWidth).Render(value))
+		b.WriteString("\n")
+	}
+
+	line("Path", module.Path)
+	project := m.projectName(module)
+	if module.Project.Version != "" {
+		project += "@" + module.Project.Version
+	}
+	if module.Project.PackageManager != "" {
+		project += " (" + module.Project.PackageManager + ")"
+	}
+	if module.Project.NodeEngine != "" {
+		project += ", node " + module.Project.NodeEngine
+	}
+	line("Project", project)
+	if module.Workspace != "" {
+		line("Workspace", module.Workspace)
+	}
+
+	source := "measured by this scan"
+	if module.FromCache {
+		source = "read from the cache of an earlier scan"
+	}
+	line("Source", source)
+
+	entry, ok := m.details[module.Path]
+	switch {
+	case !ok || entry.loading:
+		b.WriteString("\n")
+		b.WriteString(m.spinner.View() + " " + loadingPathStyle.Render("Reading details..."))
+		return m.clipPane(style, b.String())
+	case entry.err != nil:
+		b.WriteString("\n")
+		b.WriteString(deleteErrStyle.UnsetPadding().Render("Error: " + entry.err.Error()))
+		return m.clipPane(style, b.String())
+	}
+	d := entry.details
+
+	markers := "none"
+	if len(d.Markers) > 0 {
+		markers = strings.Join(d.Markers, ", ")
+	}
+	line("Markers", markers)
+
+	git := "not a git repository"
+	if d.GitRepo {
+		var state []string
+		if module.GitRef != "" {
+			state = append(state, module.GitRef)
+		}
+		if d.GitDirty {
+			state = append(state, "uncommitted changes")
+		}
+		if d.GitUnpushed {
+			state = append(state, "unpushed commits")
+		}
+		if !d.GitDirty && !d.GitUnpushed {
+			state = append(state, "clean")
+		}
+		git = strings.Join(state, ", ")
+	}
+	line("Git", git)
+
+	line("Files", fmt.Sprintf("%d", d.Files))
+	line("Size", fmt.Sprintf("%s apparent, %s on disk", utils.FormatSize(d.ApparentSize), utils.FormatSize(d.DiskSize)))
+
+	b.WriteString(statsLabelStyle.Render("Biggest packages"))
+	b.WriteString("\n")
+	packages := d.Packages
+	if len(packages) > detailMaxPackages {
+		packages = packages[:detailMaxPackages]
+	}
+	for _, pkg := range packages {
+		b.WriteString(fmt.Sprintf("%10s  %s\n", utils.FormatSize(pkg.Size), pkg.Name))
+	}
+	if len(d.Packages) == 0 {
+		b.WriteString(loadingPathStyle.Render("none"))
+	}
+
+	return m.clipPane(style, strings.TrimRight(b.String(), "\n"))
+}
+
+// clipPane renders the pane no taller than the table next to it, cutting the last lines.
+func (m model) clipPane(style lipgloss.Style, content string) string {
+	// The table box is its rows plus the frame, the pane has the same frame
+	return style.Render(clipLines(content, m.table.Height()+2))
+}
+
+// withDetailPane puts the detail pane to the right of the table when it is shown.
+func (m model) withDetailPane(tableView string) string {
+	if m.detailPaneWidth() == 0 {
+		return tableView
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, tableView, m.detailPane())
+}
@@ -0,0 +1,172 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/drxc00/sweepy/types"
+	"github.com/drxc00/sweepy/utils"
+)
+
+// Quick filters typed into the filter bar: ">500MB", "<1GB", ">90d"
+var quickFilterPattern = regexp.MustCompile(`^([<>])(\d+(?:\.\d+)?[A-Za-z]*)$`)
+
+// Spaces after the operator of a quick filter, "> 500MB" is read as ">500MB"
+var quickFilterSpace = regexp.MustCompile(`([<>])\s+(\d)`)
+
+// rowFilter is the parsed content of the filter bar.
+// Every part must match for a module to be shown.
+type rowFilter struct {
+	words []string // Fuzzy matched against the project name and path
+	tests []func(module types.ScannedNodeModule) bool
+	err   string // Why a quick filter was ignored, shown in the filter bar
+}
+
+// parseFilter splits the filter bar into fuzzy words and quick filters.
+// A quick filter that does not parse yet, like a lone ">" while typing, is ignored.
+// One without a unit, like ">90", is ignored too rather than read as bytes, and reported in err.
+func parseFilter(input string) rowFilter {
+	var f rowFilter
+	input = quickFilterSpace.ReplaceAllString(input, "$1$2")
+	for _, token := range strings.Fields(input) {
+		match := quickFilterPattern.FindStringSubmatch(token)
+		if match == nil {
+			if !strings.HasPrefix(token, ">") && !strings.HasPrefix(token, "<") {
+				f.words = append(f.words, strings.ToLower(token))
+			}
+			continue
+		}
+
+		greater := match[1] == ">"
+		if strings.TrimLeft(match[2], "0123456789.") == "" {
+			f.err = fmt.Sprintf("unknown unit in %q: add d for days or B, KB, MB, GB, TB for a size", token)
+			continue
+		}
+		if days, ok := strings.CutSuffix(strings.ToLower(match[2]), "d"); ok {
+			limit, err := utils.ParseStalenessFlagValue(days)
+			if err != nil {
+				f.err = fmt.Sprintf("days in %q must be a whole number", token)
+				continue
+			}
+			f.tests = append(f.tests, func(module types.ScannedNodeModule) bool {
+				return compareLimit(module.Staleness, limit, greater)
+			})
+			continue
+		}
+
+		limit, err := utils.ParseSize(match[2])
+		if err != nil {
+			f.err = err.Error()
+			continue
+		}
+		f.tests = append(f.tests, func(module types.ScannedNodeModule) bool {
+			return compareLimit(module.Size, limit, greater)
+		})
+	}
+	return f
+}
+
+func compareLimit(value, limit int64, greater bool) bool {
+	if greater {
+		return value > limit
+	}
+	return value < limit
+}
+
+// fuzzyMatch tells whether the letters of word appear in s in order, "rctap" matches "react-app".
+func fuzzyMatch(s, word string) bool {
+	for _, r := range word {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(string(r)):]
+	}
+	return true
+}
+
+// matchesFilter tells whether the module is shown with the current filter.
+func (m model) matchesFilter(module types.ScannedNodeModule) bool {
+	if m.filter == "" {
+		return true
+	}
+	text := strings.ToLower(m.projectName(module) + " " + module.Path)
+	for _, word := range m.rowFilter.words {
+		if !fuzzyMatch(text, word) {
+			return false
+		}
+	}
+	for _, test := range m.rowFilter.tests {
+		if !test(module) {
+			return false
+		}
+	}
+	return true
+}
+
+// visibleModules returns the modules shown with the current filter.
+func (m model) visibleModules() []types.ScannedNodeModule {
+	var visible []types.ScannedNodeModule
+	for _, module := range m.modules {
+		if m.matchesFilter(module) {
+			visible = append(visible, module)
+		}
+	}
+	return visible
+}
+
+// updateFilterInput handles the keys while the filter bar is focused.
+// The rows follow every keystroke, enter keeps the filter and esc clears it.
+func (m model) updateFilterInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key := msg.String(); key {
+	case "enter":
+		m.typingFilter = false
+		return m, nil
+	case "esc":
+		m.typingFilter = false
+		m.filter = ""
+	case "up":
+		m.table.MoveUp(1)
+		return m, nil
+	case "down":
+		m.table.MoveDown(1)
+		return m, nil
+	case "backspace":
+		if len(m.filter) > 0 {
+			runes := []rune(m.filter)
+			m.filter = string(runes[:len(runes)-1])
+		}
+	case " ":
+		m.filter += " "
+	default:
+		if msg.Type != tea.KeyRunes {
+			return m, nil
+		}
+		m.filter += string(msg.Runes)
+	}
+	m.rowFilter = parseFilter(m.filter)
+	m.refreshRows()
+	return m, nil
+}
+
+// filterBar renders the filter bar, empty when no filter is set.
+func (m model) filterBar() string {
+	if !m.typingFilter && m.filter == "" {
+		return ""
+	}
+	bar := "/" + m.filter
+	if m.typingFilter {
+		bar += "_"
+	}
+	if m.rowFilter.err != "" {
+		bar += "  ⚠ " + m.rowFilter.err
+	}
+	if m.typingFilter {
+		bar += "  (enter: keep • esc: clear • >500MB, <1GB, >90d: size and staleness)"
+	} else {
+		bar += "  (/: edit)"
+	}
+	return noticeStyle.Width(m.width).Render(bar)
+}
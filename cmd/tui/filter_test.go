@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/drxc00/sweepy/types"
+)
+
+func TestParseFilter(t *testing.T) {
+	big := types.ScannedNodeModule{Size: 600 * 1024 * 1024, Staleness: 100}
+	small := types.ScannedNodeModule{Size: 100 * 1024 * 1024, Staleness: 10}
+
+	tests := []struct {
+		name          string
+		input         string
+		expectedWords []string
+		expectedTests int
+		expectedErr   string
+		matchesBig    bool
+		matchesSmall  bool
+	}{
+		{name: "Empty", input: "", matchesBig: true, matchesSmall: true},
+		{name: "Words lowercased", input: "React App", expectedWords: []string{"react", "app"}, matchesBig: true, matchesSmall: true},
+		{name: "Bigger than", input: ">500MB", expectedTests: 1, matchesBig: true},
+		{name: "Smaller than", input: "<1GB", expectedTests: 1, matchesBig: true, matchesSmall: true},
+		{name: "Space after the operator", input: "> 500MB", expectedTests: 1, matchesBig: true},
+		{name: "Staler than", input: ">90d", expectedTests: 1, matchesBig: true},
+		{name: "Fresher than", input: "<30D", expectedTests: 1, matchesSmall: true},
+		{name: "Words and quick filters", input: "app >50d <1GB", expectedWords: []string{"app"}, expectedTests: 2, matchesBig: true},
+		{name: "Lone operator while typing", input: "app >", expectedWords: []string{"app"}, matchesBig: true, matchesSmall: true},
+		{name: "Unknown unit", input: ">5XB", expectedErr: `invalid size unit "XB": use B, KB, MB, GB or TB`, matchesBig: true, matchesSmall: true},
+		{name: "No unit is not bytes", input: ">90", expectedErr: `unknown unit in ">90": add d for days or B, KB, MB, GB, TB for a size`, matchesBig: true, matchesSmall: true},
+		{name: "Fractional days", input: ">1.5d", expectedErr: `days in ">1.5d" must be a whole number`, matchesBig: true, matchesSmall: true},
+		{name: "Operator before a word", input: "> app", expectedWords: []string{"app"}, matchesBig: true, matchesSmall: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := parseFilter(test.input)
+			if !slices.Equal(f.words, test.expectedWords) {
+				t.Errorf("Expected words %q, but got %q", test.expectedWords, f.words)
+			}
+			if len(f.tests) != test.expectedTests {
+				t.Fatalf("Expected %d quick filters, but got %d", test.expectedTests, len(f.tests))
+			}
+			if f.err != test.expectedErr {
+				t.Errorf("Expected error %q, but got %q", test.expectedErr, f.err)
+			}
+
+			matches := func(module types.ScannedNodeModule) bool {
+				for _, check := range f.tests {
+					if !check(module) {
+						return false
+					}
+				}
+				return true
+			}
+			if matches(big) != test.matchesBig {
+				t.Errorf("Expected the big module to match: %v", test.matchesBig)
+			}
+			if matches(small) != test.matchesSmall {
+				t.Errorf("Expected the small module to match: %v", test.matchesSmall)
+			}
+		})
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		s, word  string
+		expected bool
+	}{
+		{s: "react-app", word: "rctap", expected: true},
+		{s: "react-app", word: "react-app", expected: true},
+		{s: "react-app", word: "", expected: true},
+		{s: "react-app", word: "parc", expected: false},
+		{s: "react-app", word: "appp", expected: false},
+		{s: "/home/me/café/node_modules", word: "éno", expected: true},
+		{s: "", word: "a", expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s+"/"+test.word, func(t *testing.T) {
+			if got := fuzzyMatch(test.s, test.word); got != test.expected {
+				t.Errorf("Expected %v, but got %v", test.expected, got)
+			}
+		})
+	}
+}
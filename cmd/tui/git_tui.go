@@ -0,0 +1,414 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/drxc00/sweepy/internal/clean"
+	"github.com/drxc00/sweepy/internal/config"
+	"github.com/drxc00/sweepy/internal/scan"
+	"github.com/drxc00/sweepy/types"
+	"github.com/drxc00/sweepy/utils"
+)
+
+// --- Model ---
+
+// gitModel lists merged and stale branches and deletes the selected ones.
+type gitModel struct {
+	spinner   spinner.Model
+	table     table.Model
+	isLoading bool
+	branches  []types.StaleBranch
+
+	// Config
+	ctx    types.ScanContext
+	dryRun bool
+
+	progressChan chan string
+	reposFound   int
+
+	selected map[int]bool
+	status   map[int]string // [DELETED], [FAILED] or [DRY RUN] per row
+	confirm  []int          // Rows waiting for the deletion to be confirmed
+
+	keys keyMap
+	help help.Model
+
+	err           error
+	notice        string
+	width, height int
+}
+
+type gitScanResultMsg struct {
+	branches []types.StaleBranch
+	err      error
+}
+
+type gitDeleteMsg struct {
+	errs map[int]error // Only the failed deletions
+	done []int
+}
+
+// ScanGit starts the branch cleanup TUI.
+func ScanGit(ctx types.ScanContext, cfg config.Config, dryRun bool) {
+	applyTheme(themeFor(cfg), cfg.StalenessThresholds)
+	s := spinner.New()
+	s.Spinner = spinner.MiniDot
+	s.Style = lipgloss.NewStyle().Foreground(colorPrimary).Bold(true)
+
+	m := gitModel{
+		spinner:      s,
+		isLoading:    true,
+		ctx:          ctx,
+		dryRun:       dryRun,
+		progressChan: make(chan string, 1000),
+		selected:     make(map[int]bool),
+		status:       make(map[int]string),
+		keys:         newKeyMap(cfg.Keys),
+		help:         newHelp(),
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		utils.Log("Error when scanning git repositories: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func DeleteBranches(branches []types.StaleBranch, indexes []int, roots []string) tea.Msg {
+	msg := gitDeleteMsg{errs: make(map[int]error)}
+	for _, idx := range indexes {
+		if err := clean.DeleteBranch(branches[idx], roots); err != nil {
+			utils.Log("Error deleting branch %s: %v\n", branches[idx].Name, err)
+			msg.errs[idx] = err
+			continue
+		}
+		msg.done = append(msg.done, idx)
+	}
+	return msg
+}
+
+// --- BubbleTea Handlers ---
+
+func (m gitModel) Init() tea.Cmd {
+	return tea.Batch(
+		m.spinner.Tick,
+		func() tea.Msg {
+			branches, err := scan.GitScan(m.ctx, m.progressChan)
+			return gitScanResultMsg{branches: branches, err: err}
+		},
+		ListenForProgress(m.progressChan),
+	)
+}
+
+func (m gitModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	nm, cmd := m.update(msg)
+	if gm, ok := nm.(gitModel); ok {
+		gm.layout()
+		nm = gm
+	}
+	return nm, cmd
+}
+
+func (m gitModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		if m.confirm != nil {
+			return m.updateConfirm(msg)
+		}
+		if key.Matches(msg, m.keys.Quit) {
+			return m, tea.Quit
+		}
+		if m.isLoading {
+			return m, nil
+		}
+
+		switch {
+		case key.Matches(msg, m.keys.Up):
+			m.table.MoveUp(1)
+		case key.Matches(msg, m.keys.Down):
+			m.table.MoveDown(1)
+		case key.Matches(msg, m.keys.Select):
+			idx := m.table.Cursor()
+			if idx >= 0 && idx < len(m.branches) && m.status[idx] != "[DELETED]" {
+				m.selected[idx] = !m.selected[idx]
+			}
+		case key.Matches(msg, m.keys.SelectAll):
+			// Select all, or none if everything is selected already
+			all := true
+			for i := range m.branches {
+				if !m.selected[i] && m.status[i] != "[DELETED]" {
+					all = false
+				}
+			}
+			for i := range m.branches {
+				m.selected[i] = !all && m.status[i] != "[DELETED]"
+			}
+		case key.Matches(msg, m.keys.Delete, m.keys.Open):
+			var indexes []int
+			for i := range m.branches {
+				if m.selected[i] {
+					indexes = append(indexes, i)
+				}
+			}
+			if len(indexes) == 0 {
+				m.notice = "Nothing selected, use " + m.keys.Select.Help().Key + " to select branches."
+				break
+			}
+
+			if m.dryRun {
+				for _, i := range indexes {
+					m.status[i] = "[DRY RUN]"
+					m.selected[i] = false
+				}
+				m.notice = fmt.Sprintf("Dry run: %d branches would be deleted.", len(indexes))
+				break
+			}
+
+			m.confirm, m.notice = indexes, ""
+		}
+		m.refreshRows()
+
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.help.Width = m.width - 4
+		if !m.isLoading {
+			m.table.SetColumns(fitColumns(branchLayout, m.width-6))
+			m.table.SetWidth(m.width - 6)
+		}
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case scanProgressMsg:
+		if strings.HasPrefix(msg.path, "Scanning ") {
+			m.reposFound++
+		}
+		return m, ListenForProgress(m.progressChan)
+
+	case gitScanResultMsg:
+		m.isLoading = false
+		m.branches = msg.branches
+		m.err = msg.err
+		if m.err != nil {
+			return m, nil
+		}
+
+		columns := fitColumns(branchLayout, m.width-6)
+
+		t := table.New(
+			table.WithColumns(columns),
+			table.WithFocused(true),
+		)
+
+		t.SetStyles(tableStyles())
+		t.SetWidth(m.width - 6)
+
+		m.table = t
+		m.refreshRows()
+		return m, nil
+
+	case gitDeleteMsg:
+		for _, i := range msg.done {
+			m.status[i] = "[DELETED]"
+			m.selected[i] = false
+		}
+		for i := range msg.errs {
+			m.status[i] = "[FAILED]"
+		}
+		m.notice = fmt.Sprintf("Deleted %d branches, %d failed. Run `sweepy git --undo` to restore them.", len(msg.done), len(msg.errs))
+		m.refreshRows()
+	}
+
+	return m, nil
+}
+
+// updateConfirm handles the keys while the deletion waits for a y or an n.
+func (m gitModel) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y":
+		branches, indexes, roots := m.branches, m.confirm, m.ctx.Roots()
+		m.confirm = nil
+		return m, func() tea.Msg {
+			return DeleteBranches(branches, indexes, roots)
+		}
+	case "n", "esc":
+		m.confirm, m.notice = nil, "Cancelled."
+	}
+	return m, nil
+}
+
+// layout gives the table the rows left by the stats, the notice and the footer.
+func (m *gitModel) layout() {
+	if m.height == 0 || m.isLoading || m.err != nil {
+		return
+	}
+	setTableHeight(&m.table, tableHeight(m.height, m.headerView(), m.footerView()))
+}
+
+// repoName is the repository of b relative to the scanned path.
+func (m gitModel) repoName(b types.StaleBranch) string {
+	repo, err := filepath.Rel(m.ctx.Path, b.Repo)
+	if err != nil || repo == "." {
+		repo = filepath.Base(b.Repo)
+	}
+	return repo
+}
+
+// refreshRows rebuilds the table rows from the branches, selection and status.
+func (m *gitModel) refreshRows() {
+	rows := make([]table.Row, 0, len(m.branches))
+	for i, b := range m.branches {
+		check := "[ ]"
+		if m.selected[i] {
+			check = "[x]"
+		}
+
+		name := b.Name
+		if m.status[i] != "" {
+			name = m.status[i] + " " + name
+		}
+
+		var reasons []string
+		if b.Merged {
+			reasons = append(reasons, "merged into "+b.DefaultBranch)
+		}
+		if m.ctx.Staleness != 0 && b.Staleness >= m.ctx.Staleness {
+			reasons = append(reasons, "stale")
+		}
+
+		rows = append(rows, table.Row{
+			check,
+			m.repoName(b),
+			name,
+			b.LastCommit.Format("2006-01-02"),
+			utils.ColorCodedStaleness(b.Staleness),
+			strings.Join(reasons, ", "),
+		})
+	}
+	m.table.SetRows(rows)
+}
+
+func (m gitModel) View() string {
+	if m.err != nil {
+		return errorStyle.Render(fmt.Sprintf(
+			"\nError: %s\n\nPress q to quit.",
+			m.err.Error(),
+		))
+	}
+
+	if m.confirm != nil {
+		return m.confirmContent()
+	}
+
+	if m.isLoading {
+		var b strings.Builder
+		b.WriteString("\n")
+		b.WriteString(loadingTitleStyle.Render("📦 SWEEPY 📦"))
+		b.WriteString("\n\n")
+		b.WriteString(scanningStatusStyle.Render(fmt.Sprintf(
+			"%s %s %s",
+			m.spinner.View(),
+			scanningLabelStyle.Render("Scanning for git repositories..."),
+			scanningCountStyle.Render(fmt.Sprintf("(%d found)", m.reposFound)),
+		)))
+		b.WriteString("\n")
+		b.WriteString(loadingFooterStyle.Render("Press " + m.keys.Quit.Help().Key + " or Ctrl+C to quit"))
+		return b.String()
+	}
+
+	tableBorder := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(colorBorder).
+		Padding(1, 1)
+	return m.headerView() + tableBorder.Render(m.table.View()) + m.footerView()
+}
+
+// headerView renders the title and the stats above the table.
+func (m gitModel) headerView() string {
+	var b strings.Builder
+	b.WriteString("\n")
+	b.WriteString(titleStyle.Render("📦 SWEEPY 📦"))
+	b.WriteString("\n\n")
+
+	selected := 0
+	for _, ok := range m.selected {
+		if ok {
+			selected++
+		}
+	}
+	mode := "delete"
+	if m.dryRun {
+		mode = "dry run"
+	}
+	stats := fmt.Sprintf(
+		"%s %s\n%s %s\n%s %s\n",
+		statsLabelStyle.Render("Found:"),
+		statsValueStyle.Render(fmt.Sprintf("%d branches in %d repositories", len(m.branches), m.reposFound)),
+		statsLabelStyle.Render("Selected:"),
+		statsValueStyle.Render(fmt.Sprintf("%d branches", selected)),
+		statsLabelStyle.Render("Mode:"),
+		statsValueStyle.Render(mode),
+	)
+	b.WriteString(statsStyle.MaxWidth(m.width).Render(stats))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// footerView renders the notice and the keys below the table.
+func (m gitModel) footerView() string {
+	var b strings.Builder
+	if m.notice != "" {
+		b.WriteString("\n")
+		b.WriteString(noticeStyle.Width(m.width).Render(m.notice))
+	}
+
+	b.WriteString("\n")
+	footer := lipgloss.NewStyle().
+		Foreground(colorSecondary).
+		Align(lipgloss.Center).
+		Width(m.width - 4).
+		Render(m.help.ShortHelpView(m.keys.gitHelp()))
+	b.WriteString(footer)
+	return b.String()
+}
+
+// confirmContent asks before the selected branches are deleted.
+func (m gitModel) confirmContent() string {
+	var b strings.Builder
+	b.WriteString(statsLabelStyle.Render(fmt.Sprintf("Delete %d branches?", len(m.confirm))))
+	b.WriteString("\n\n")
+
+	shown := m.confirm
+	if limit := min(confirmMaxPaths, max(m.height-12, 1)); len(shown) > limit {
+		shown = shown[:limit]
+	}
+	for _, i := range shown {
+		b.WriteString(fmt.Sprintf("%s  %s\n", m.repoName(m.branches[i]), m.branches[i].Name))
+	}
+	if more := len(m.confirm) - len(shown); more > 0 {
+		b.WriteString(loadingPathStyle.Render(fmt.Sprintf("... and %d more", more)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(statsValueStyle.Render("They can be restored with `sweepy git --undo`."))
+	b.WriteString("\n\n")
+	b.WriteString(loadingPathStyle.Render("y: confirm • n/esc: cancel"))
+
+	box := fitBox(confirmBoxStyle, b.String(), m.width)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
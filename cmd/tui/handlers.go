@@ -5,15 +5,17 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/drxc00/sweepy/internal/clean"
+	"github.com/drxc00/sweepy/internal/config"
 	"github.com/drxc00/sweepy/internal/scan"
 	"github.com/drxc00/sweepy/types"
 	"github.com/drxc00/sweepy/utils"
 )
 
 // Function that starts the scan
-func ScanNode(ctx types.ScanContext) {
+func ScanNode(ctx types.ScanContext, cfg config.Config, noConfirm bool) {
+	applyTheme(themeFor(cfg), cfg.StalenessThresholds)
 	p := tea.NewProgram(
-		initialModel(ctx),
+		initialModel(ctx, cfg, noConfirm),
 		tea.WithAltScreen(),
 	)
 
@@ -23,13 +25,41 @@ func ScanNode(ctx types.ScanContext) {
 	}
 }
 
-func DeleteNode(n types.ScannedNodeModule, idx int) tea.Msg {
-	err := clean.CleanNodeModule(n.Path)
+// RunAction applies action to the modules with the batch engine, their progress is sent on ch.
+func RunAction(modules []types.ScannedNodeModule, action clean.Action, ch chan clean.BatchProgress) tea.Cmd {
+	paths := make([]string, 0, len(modules))
+	for _, n := range modules {
+		paths = append(paths, n.Path)
+	}
+	return func() tea.Msg {
+		clean.BatchRun(paths, action, clean.DefaultWorkers, ch)
+		return nil
+	}
+}
+
+// ListenForDeletes waits for the next progress of a deletion.
+func ListenForDeletes(ch chan clean.BatchProgress) tea.Cmd {
+	return func() tea.Msg {
+		return deleteProgressMsg(<-ch)
+	}
+}
+
+// LoadBreakdown measures the packages installed in the node_modules directory p.
+func LoadBreakdown(p string) tea.Msg {
+	packages, err := scan.PackageBreakdown(p)
+	if err != nil {
+		utils.Log("Error measuring packages: %v\n", err)
+	}
+	return breakdownMsg{path: p, packages: packages, err: err}
+}
+
+// LoadDetails reads what the detail pane shows about the node_modules at p.
+func LoadDetails(p string) tea.Msg {
+	details, err := scan.ArtifactDetails(p)
 	if err != nil {
-		utils.Log("Error deleting node_module: %v\n", err)
-		return deleteErrMsg{err: err, index: idx, path: n.Path}
+		utils.Log("Error reading details: %v\n", err)
 	}
-	return deleteSuccessMsg{path: n.Path, index: idx, size: n.Size}
+	return detailsMsg{path: p, details: details, err: err}
 }
 
 func StartScan(ctx types.ScanContext, progressChan chan string) tea.Cmd {
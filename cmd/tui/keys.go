@@ -0,0 +1,192 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/drxc00/sweepy/internal/config"
+)
+
+// keyMap holds the bindings of the node_modules list, the tree, the package breakdown
+// and the branch cleanup.
+// Each can be remapped from the config by its action name, see config.KeyActions.
+type keyMap struct {
+	Quit          key.Binding
+	Help          key.Binding
+	Up            key.Binding
+	Down          key.Binding
+	Open          key.Binding
+	Expand        key.Binding
+	Collapse      key.Binding
+	Back          key.Binding
+	Select        key.Binding
+	SelectAll     key.Binding
+	SelectNone    key.Binding
+	Invert        key.Binding
+	OlderThan     key.Binding
+	Filter        key.Binding
+	Sort          key.Binding
+	Reverse       key.Binding
+	Delete        key.Binding
+	Trash         key.Binding
+	Archive       key.Binding
+	Details       key.Binding
+	Tree          key.Binding
+	Rescan        key.Binding
+	RescanNoCache key.Binding
+}
+
+// newKeyMap builds the bindings from config.DefaultKeys, replacing the keys of the actions set in overrides.
+func newKeyMap(overrides map[string][]string) keyMap {
+	binding := func(action, desc string) key.Binding {
+		custom, ok := overrides[action]
+		if !ok {
+			custom = config.DefaultKeys[action]
+		}
+		keys := make([]string, 0, len(custom))
+		for _, k := range custom {
+			if k == "space" {
+				k = " " // How bubbletea names the space bar
+			}
+			keys = append(keys, k)
+		}
+		return key.NewBinding(key.WithKeys(keys...), key.WithHelp(keyHelp(keys), desc))
+	}
+
+	return keyMap{
+		Quit:          binding("quit", "quit"),
+		Help:          binding("help", "help"),
+		Up:            binding("up", "up"),
+		Down:          binding("down", "down"),
+		Open:          binding("open", "packages / open"),
+		Expand:        binding("expand", "expand"),
+		Collapse:      binding("collapse", "collapse"),
+		Back:          binding("back", "back"),
+		Select:        binding("select", "select"),
+		SelectAll:     binding("selectAll", "select all"),
+		SelectNone:    binding("selectNone", "select none"),
+		Invert:        binding("invert", "invert selection"),
+		OlderThan:     binding("olderThan", "select older than"),
+		Filter:        binding("filter", "filter"),
+		Sort:          binding("sort", "sort column"),
+		Reverse:       binding("reverse", "reverse sort"),
+		Delete:        binding("delete", "delete"),
+		Trash:         binding("trash", "trash"),
+		Archive:       binding("archive", "archive"),
+		Details:       binding("details", "details"),
+		Tree:          binding("tree", "tree / list"),
+		Rescan:        binding("rescan", "rescan"),
+		RescanNoCache: binding("rescanNoCache", "rescan without cache"),
+	}
+}
+
+// keyHelp shows keys the way the help lists them, "↑/k".
+func keyHelp(keys []string) string {
+	names := make([]string, 0, len(keys))
+	for _, k := range keys {
+		switch k {
+		case " ":
+			k = "space"
+		case "up":
+			k = "↑"
+		case "down":
+			k = "↓"
+		case "left":
+			k = "←"
+		case "right":
+			k = "→"
+		}
+		names = append(names, k)
+	}
+	return strings.Join(names, "/")
+}
+
+// ShortHelp is what the footer of the list shows.
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Select, k.Delete, k.Filter, k.Sort, k.Tree, k.Help, k.Quit}
+}
+
+// FullHelp is what the ? overlay shows, one column per group.
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Open, k.Expand, k.Collapse, k.Back},
+		{k.Select, k.SelectAll, k.SelectNone, k.Invert, k.OlderThan},
+		{k.Delete, k.Trash, k.Archive, k.Filter, k.Sort, k.Reverse},
+		{k.Details, k.Tree, k.Rescan, k.RescanNoCache, k.Help, k.Quit},
+	}
+}
+
+// treeHelp is what the footer of the tree view shows.
+func (k keyMap) treeHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Open, k.Collapse, k.Select, k.Delete, k.Trash, k.Archive, k.Tree, k.Help, k.Quit}
+}
+
+// breakdownHelp is what the footer of the package breakdown shows.
+func (k keyMap) breakdownHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Back, k.Quit}
+}
+
+// gitHelp is what the footer of the branch cleanup shows.
+func (k keyMap) gitHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Select, k.SelectAll, k.Delete, k.Quit}
+}
+
+// newHelp styles the help in the colors of the footer.
+func newHelp() help.Model {
+	h := help.New()
+	h.Styles.ShortKey = lipgloss.NewStyle().Foreground(colorPrimary)
+	h.Styles.ShortDesc = lipgloss.NewStyle().Foreground(colorSecondary)
+	h.Styles.ShortSeparator = lipgloss.NewStyle().Foreground(colorBorder)
+	h.Styles.FullKey = h.Styles.ShortKey
+	h.Styles.FullDesc = h.Styles.ShortDesc
+	h.Styles.FullSeparator = h.Styles.ShortSeparator
+	return h
+}
+
+// fullHelpView lays the groups of keys side by side, or stacks them on a narrow terminal.
+func (m model) fullHelpView() string {
+	groups := m.keys.FullHelp()
+	h := m.help
+	h.Width = 0 // Wrapped here rather than cut
+	for perRow := len(groups); ; perRow /= 2 {
+		var rows []string
+		for i := 0; i < len(groups); i += perRow {
+			rows = append(rows, h.FullHelpView(groups[i:min(i+perRow, len(groups))]))
+		}
+		view := strings.Join(rows, "\n\n")
+		if perRow == 1 || lipgloss.Width(view)+helpBoxStyle.GetHorizontalFrameSize() <= m.width {
+			return view
+		}
+	}
+}
+
+// helpContent renders every binding in the middle of the screen.
+// A short terminal gets the keys alone, cut to the lines that fit.
+func (m model) helpContent() string {
+	closing := loadingPathStyle.Render("Press any key to close")
+	notes := ""
+	if !m.compact() {
+		notes = loadingPathStyle.Render("Dialogs answer to y, n and esc, the filter and older-than inputs to enter and esc.\nctrl+c always quits. Keys can be remapped in " + config.ConfigFile + ".")
+	}
+
+	var b strings.Builder
+	b.WriteString(statsLabelStyle.Render("Keys"))
+	b.WriteString("\n\n")
+	// The title, the closing line and the blank lines around them
+	limit := m.height - helpBoxStyle.GetVerticalFrameSize() - 4
+	if notes != "" {
+		limit -= lipgloss.Height(notes) + 1
+	}
+	b.WriteString(clipLines(m.fullHelpView(), limit))
+	b.WriteString("\n\n")
+	if notes != "" {
+		b.WriteString(notes)
+		b.WriteString("\n\n")
+	}
+	b.WriteString(closing)
+
+	box := fitBox(helpBoxStyle, b.String(), m.width)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,169 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Below this height the stats are put on one line to leave the rows to the table.
+const compactHeight = 30
+
+// Fewest rows a table is given, however small the terminal
+const minTableHeight = 3
+
+// Lines of the rounded border and padding around a table
+const tableFrameHeight = 4
+
+// layoutColumn is a column of a table that adapts to the width of the terminal.
+type layoutColumn struct {
+	title string
+	ratio float64 // Share of the width left once every column has its minimum
+	min   int     // Narrowest width the values are still readable at
+	drop  int     // Order in which the column is dropped when the table gets narrow, 0 never
+}
+
+var listLayout = []layoutColumn{
+	{title: "", min: 4},
+	{title: "PROJECT", ratio: 0.15, min: 12},
+	{title: "PATH", ratio: 0.26, min: 20, drop: 5},
+	{title: "SIZE", ratio: 0.09, min: 9},
+	{title: "LAST MODIFIED", ratio: 0.13, min: 19, drop: 3},
+	{title: "STALENESS", ratio: 0.09, min: 11, drop: 6},
+	{title: "LOCK", ratio: 0.10, min: 12, drop: 4},
+	{title: "PM", ratio: 0.06, min: 5, drop: 2},
+	{title: "NODE", ratio: 0.08, min: 8, drop: 1},
+}
+
+var treeLayout = []layoutColumn{
+	{title: "", min: 4},
+	{title: "DIRECTORY", ratio: 0.55, min: 16},
+	{title: "SIZE", ratio: 0.15, min: 9},
+	{title: "SHARE", ratio: 0.18, min: 15, drop: 2},
+	{title: "NODE_MODULES", ratio: 0.12, min: 12, drop: 1},
+}
+
+var breakdownLayout = []layoutColumn{
+	{title: "PACKAGE", ratio: 0.40, min: 16},
+	{title: "VERSION", ratio: 0.15, min: 8, drop: 3},
+	{title: "SIZE", ratio: 0.15, min: 9},
+	{title: "NESTED COPIES", ratio: 0.15, min: 13, drop: 1},
+	{title: "NESTED SIZE", ratio: 0.15, min: 11, drop: 2},
+}
+
+var branchLayout = []layoutColumn{
+	{title: "", min: 4},
+	{title: "REPOSITORY", ratio: 0.30, min: 14},
+	{title: "BRANCH", ratio: 0.25, min: 14},
+	{title: "LAST COMMIT", ratio: 0.15, min: 11, drop: 2},
+	{title: "AGE", ratio: 0.10, min: 9, drop: 3},
+	{title: "REASON", ratio: 0.15, min: 12, drop: 1},
+}
+
+// fitColumns sizes the columns to width. The columns are dropped in their drop order until
+// the others fit at their minimum width, then the width left is shared by ratio.
+// A dropped column keeps its place with a width of 0, which the table skips, so the rows
+// do not change with the layout.
+func fitColumns(layout []layoutColumn, width int) []table.Column {
+	const cellPadding = 2 // Of the table cell style
+
+	visible := make([]bool, len(layout))
+	for i := range visible {
+		visible[i] = true
+	}
+	needed := func() (total int, ratio float64) {
+		for i, column := range layout {
+			if visible[i] {
+				total += column.min + cellPadding
+				ratio += column.ratio
+			}
+		}
+		return total, ratio
+	}
+
+	total, ratio := needed()
+	for total > width {
+		next := -1
+		for i, column := range layout {
+			if visible[i] && column.drop > 0 && (next < 0 || column.drop < layout[next].drop) {
+				next = i
+			}
+		}
+		if next < 0 {
+			break // What is left never drops, the table truncates it
+		}
+		visible[next] = false
+		total, ratio = needed()
+	}
+
+	spare := max(width-total, 0)
+	columns := make([]table.Column, len(layout))
+	for i, column := range layout {
+		columns[i].Title = column.title
+		if !visible[i] {
+			continue
+		}
+		columns[i].Width = column.min
+		if ratio > 0 {
+			columns[i].Width += int(float64(spare) * column.ratio / ratio)
+		}
+	}
+	return columns
+}
+
+// compact tells whether the terminal is too short for the full stats.
+func (m model) compact() bool {
+	return m.height < compactHeight
+}
+
+// tableHeight is what is left to a table, between the header and the footer and inside its frame.
+// The header ends on the line the table starts on and the footer starts on the line it ends on.
+func tableHeight(height int, header, footer string) int {
+	return max(height-(lipgloss.Height(header)-1)-(lipgloss.Height(footer)-1)-tableFrameHeight, minTableHeight)
+}
+
+// setTableHeight resizes t only when its height changes, resizing resets its scrolling.
+func setTableHeight(t *table.Model, height int) {
+	const headerHeight = 2 // Titles and the line under them
+	if t.Height() != height-headerHeight {
+		t.SetHeight(height)
+	}
+}
+
+// layout gives the tables the rows left by the stats, the notices and the footer,
+// which come and go as the user works.
+func (m *model) layout() {
+	if m.height == 0 {
+		return
+	}
+	if m.breakdown != nil && !m.breakdown.loading {
+		setTableHeight(&m.breakdown.table, tableHeight(m.height, m.breakdownHeaderView(), m.breakdownFooterView()))
+	}
+	if !m.scanComplete {
+		return
+	}
+	height := tableHeight(m.height, m.headerView(), m.footerView())
+	if m.tree != nil {
+		setTableHeight(&m.tree.table, height)
+	}
+	setTableHeight(&m.table, height)
+}
+
+// fitBox renders content in the box style, wrapping it when the box is wider than the terminal.
+func fitBox(style lipgloss.Style, content string, width int) string {
+	box := style.Render(content)
+	if lipgloss.Width(box) > width {
+		box = style.Width(width - style.GetHorizontalBorderSize()).Render(content)
+	}
+	return box
+}
+
+// clipLines keeps the first limit lines of s, the last one kept becomes an ellipsis.
+func clipLines(s string, limit int) string {
+	lines := strings.Split(s, "\n")
+	if limit < 1 || len(lines) <= limit {
+		return s
+	}
+	return strings.Join(append(lines[:limit-1], loadingPathStyle.Render("…")), "\n")
+}
@@ -0,0 +1,88 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFitColumns(t *testing.T) {
+	layout := []layoutColumn{
+		{title: "A", min: 4},
+		{title: "B", ratio: 0.5, min: 10, drop: 2},
+		{title: "C", ratio: 0.5, min: 10, drop: 1},
+	}
+
+	tests := []struct {
+		name     string
+		width    int
+		expected []int
+	}{
+		{name: "Spare width shared by ratio", width: 40, expected: []int{4, 15, 15}},
+		{name: "Exact fit", width: 30, expected: []int{4, 10, 10}},
+		{name: "First drop", width: 29, expected: []int{4, 21, 0}},
+		{name: "Second drop", width: 17, expected: []int{4, 0, 0}},
+		{name: "Never dropped", width: 3, expected: []int{4, 0, 0}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			columns := fitColumns(layout, test.width)
+			var widths []int
+			for i, column := range columns {
+				widths = append(widths, column.Width)
+				if column.Title != layout[i].title {
+					t.Errorf("Expected column %d titled %q, but got %q", i, layout[i].title, column.Title)
+				}
+			}
+			if !slices.Equal(widths, test.expected) {
+				t.Errorf("Expected widths %v, but got %v", test.expected, widths)
+			}
+		})
+	}
+}
+
+// The tables of the TUI must fit every width their fixed columns fit in.
+func TestFitColumnsLayouts(t *testing.T) {
+	const cellPadding = 2
+
+	layouts := map[string][]layoutColumn{
+		"list":      listLayout,
+		"tree":      treeLayout,
+		"breakdown": breakdownLayout,
+		"branch":    branchLayout,
+	}
+
+	for name, layout := range layouts {
+		t.Run(name, func(t *testing.T) {
+			fixed := 0
+			for _, column := range layout {
+				if column.drop == 0 {
+					fixed += column.min + cellPadding
+				}
+			}
+
+			for width := fixed; width <= 240; width++ {
+				columns := fitColumns(layout, width)
+				if len(columns) != len(layout) {
+					t.Fatalf("Expected %d columns at width %d, but got %d", len(layout), width, len(columns))
+				}
+				total := 0
+				for i, column := range columns {
+					if column.Width == 0 {
+						if layout[i].drop == 0 {
+							t.Errorf("Expected %q never to be dropped, but it was at width %d", layout[i].title, width)
+						}
+						continue
+					}
+					if column.Width < layout[i].min {
+						t.Errorf("Expected %q at least %d wide at width %d, but got %d", layout[i].title, layout[i].min, width, column.Width)
+					}
+					total += column.Width + cellPadding
+				}
+				if total > width {
+					t.Errorf("Expected the columns to fit in %d, but they take %d", width, total)
+				}
+			}
+		})
+	}
+}
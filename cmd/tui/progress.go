@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/drxc00/sweepy/utils"
+)
+
+// Deletions listed one by one under the table, the others only count in the overall bar
+const maxProgressLines = 5
+
+// deleteProgressView renders a bar per deletion in flight and one for all of them.
+func (m model) deleteProgressView() string {
+	if len(m.deleting) == 0 {
+		return ""
+	}
+
+	paths := make([]string, 0, len(m.deleting))
+	for p := range m.deleting {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+
+	sizes := make(map[string]int64, len(m.modules))
+	for _, module := range m.modules {
+		sizes[module.Path] = module.Size
+	}
+
+	var b strings.Builder
+	for i, p := range paths {
+		if i == maxProgressLines {
+			b.WriteString(fmt.Sprintf("  … and %d more\n", len(paths)-maxProgressLines))
+			break
+		}
+		progress := m.deleting[p]
+		b.WriteString(fmt.Sprintf("  %s %3d%%  %d files, %s  %s\n",
+			utils.ProgressBar(progress.Bytes, sizes[p], 20),
+			utils.Percent(progress.Bytes, sizes[p]),
+			progress.Files,
+			utils.FormatSize(progress.Bytes),
+			utils.FormatPath(p, m.ctx.Path),
+		))
+	}
+
+	b.WriteString(fmt.Sprintf("  %s %3d%%  %s of %s, %d left",
+		utils.ProgressBar(m.batchBytes, m.batchTotal, 20),
+		utils.Percent(m.batchBytes, m.batchTotal),
+		utils.FormatSize(m.batchBytes),
+		utils.FormatSize(m.batchTotal),
+		len(paths),
+	))
+	return deletingStyle.MaxWidth(m.width).Render(b.String())
+}
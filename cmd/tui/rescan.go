@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/drxc00/sweepy/types"
+	"github.com/drxc00/sweepy/utils"
+)
+
+// rescan scans the root again while the current results stay on screen.
+// force skips the cache and replaces it with the new results.
+func (m model) rescan(force bool) (tea.Model, tea.Cmd) {
+	if !m.scanComplete || m.rescanning {
+		return m, nil
+	}
+	if len(m.deleting) > 0 {
+		m.notice = "Wait for the running actions to finish before rescanning."
+		return m, nil
+	}
+
+	ctx := m.ctx
+	if force {
+		ctx.NoCache, ctx.ResetCache = true, true
+	}
+	m.rescanning = true
+	m.scanningPaths = nil
+	m.progressChan = make(chan string, 1000)
+	m.notice = ""
+	return m, StartScan(ctx, m.progressChan)
+}
+
+// applyRescan swaps in the results of a rescan. The sort, filter, selection and collapsed
+// workspaces are kept and the cursor stays on the same row if it is still there.
+// Rows that failed to delete are checked again, the ones that are gone now are dropped.
+func (m model) applyRescan(msg scanResultMsg) model {
+	m.rescanning = false
+	if msg.err != nil {
+		utils.Log("Error rescanning: %v\n", msg.err)
+		m.notice = "Rescan failed: " + msg.err.Error()
+		return m
+	}
+
+	// What the cursor is on: a node_modules path or a workspace root
+	current := ""
+	if row, ok := m.selectedRow(); ok {
+		if row.isHeader() {
+			current = row.workspace
+		} else {
+			current = m.modules[row.module].Path
+		}
+	}
+
+	before := make(map[string]bool, len(m.modules))
+	for _, module := range m.modules {
+		before[module.Path] = true
+	}
+
+	var modules []types.ScannedNodeModule
+	for _, module := range msg.modules {
+		if m.status[module.Path] == statusFailed {
+			// A cached scan still lists it, even if a retry outside sweepy removed it since
+			if _, err := os.Lstat(module.Path); os.IsNotExist(err) {
+				continue
+			}
+		}
+		modules = append(modules, module)
+	}
+
+	found := make(map[string]bool, len(modules))
+	added := 0
+	m.totalSize, m.avgStaleness = moduleTotals(modules)
+	for _, module := range modules {
+		found[module.Path] = true
+		if !before[module.Path] {
+			added++
+		}
+		if _, ok := m.collapsed[module.Workspace]; module.Workspace != "" && !ok {
+			m.collapsed[module.Workspace] = true
+		}
+	}
+	removed := 0
+	for path := range before {
+		if !found[path] {
+			removed++
+		}
+	}
+
+	// Failures are measured again, a row that is still there can be retried
+	for path, status := range m.status {
+		if status == statusFailed {
+			delete(m.status, path)
+		}
+	}
+	for path := range m.selected {
+		if !found[path] {
+			delete(m.selected, path)
+		}
+	}
+	m.details = make(map[string]*detailEntry)
+	m.deleteErr = nil
+
+	m.modules = modules
+	m.scanDuration = msg.stats.ScanDuration.String()
+	m.refreshRows()
+	for i, row := range m.rows {
+		if (row.isHeader() && row.workspace == current) || (!row.isHeader() && m.modules[row.module].Path == current) {
+			m.table.SetCursor(i)
+			break
+		}
+	}
+
+	m.notice = fmt.Sprintf("Rescanned %s: %d node_modules directories, %d new, %d gone.", filepath.Clean(m.ctx.Path), len(modules), added, removed)
+	return m
+}
+
+// rescanStatus renders the progress of a rescan, empty when none is running.
+func (m model) rescanStatus() string {
+	if !m.rescanning {
+		return ""
+	}
+	return noticeStyle.Width(m.width).Render(fmt.Sprintf("%s Rescanning %s... (%d found)", m.spinner.View(), m.ctx.Path, len(m.scanningPaths)))
+}
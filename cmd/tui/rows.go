@@ -0,0 +1,192 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/drxc00/sweepy/types"
+	"github.com/drxc00/sweepy/utils"
+)
+
+// tableRow is what a line of the table stands for.
+// Members of a workspace are listed under a header row that can be collapsed.
+type tableRow struct {
+	workspace string // Workspace root, set on headers and members
+	module    int    // Index in model.modules, -1 for a workspace header
+}
+
+func (r tableRow) isHeader() bool {
+	return r.module < 0
+}
+
+// workspaceMembers returns the indexes of the modules that belong to the workspace at root
+// and match the filter.
+func (m model) workspaceMembers(root string) []int {
+	var members []int
+	for i, module := range m.modules {
+		if module.Workspace == root && m.matchesFilter(module) {
+			members = append(members, i)
+		}
+	}
+	return members
+}
+
+// buildRows groups the modules matching the filter by workspace and orders them by the active sort.
+// A workspace is placed by the totals of its members, the members are sorted under it.
+// A workspace with a single node_modules is shown as a plain row.
+func (m model) buildRows() []tableRow {
+	counts := make(map[string]int)
+	visible := make([]bool, len(m.modules))
+	for i, module := range m.modules {
+		visible[i] = m.matchesFilter(module)
+		if visible[i] && module.Workspace != "" {
+			counts[module.Workspace]++
+		}
+	}
+
+	// A unit is a plain row or a whole workspace, they are sorted together
+	type unit struct {
+		row tableRow
+		key sortKey
+	}
+	var units []unit
+	seen := make(map[string]bool)
+	for i, module := range m.modules {
+		if !visible[i] {
+			continue
+		}
+		if counts[module.Workspace] < 2 {
+			units = append(units, unit{tableRow{module: i}, m.moduleSortKey(i)})
+			continue
+		}
+		if seen[module.Workspace] {
+			continue
+		}
+		seen[module.Workspace] = true
+		units = append(units, unit{tableRow{workspace: module.Workspace, module: -1}, m.workspaceSortKey(module.Workspace)})
+	}
+	slices.SortStableFunc(units, func(a, b unit) int {
+		return m.compareSortKeys(a.key, b.key)
+	})
+
+	rows := make([]tableRow, 0, len(m.modules))
+	for _, u := range units {
+		rows = append(rows, u.row)
+		if !u.row.isHeader() || m.collapsed[u.row.workspace] {
+			continue
+		}
+		members := m.workspaceMembers(u.row.workspace)
+		m.sortModules(members)
+		for _, idx := range members {
+			rows = append(rows, tableRow{workspace: u.row.workspace, module: idx})
+		}
+	}
+	return rows
+}
+
+// refreshRows rebuilds the table, and the tree when it is shown, from the modules.
+func (m *model) refreshRows() {
+	m.rows = m.buildRows()
+
+	rows := make([]table.Row, 0, len(m.rows))
+	for _, r := range m.rows {
+		if r.isHeader() {
+			rows = append(rows, m.workspaceRow(r.workspace))
+			continue
+		}
+		row := m.moduleRow(m.modules[r.module])
+		if r.workspace != "" {
+			row[1] = "  └ " + row[1]
+		}
+		rows = append(rows, row)
+	}
+	m.table.SetRows(rows)
+
+	if m.table.Cursor() >= len(rows) && len(rows) > 0 {
+		m.table.SetCursor(len(rows) - 1)
+	}
+
+	if m.tree != nil {
+		m.refreshTree()
+	}
+}
+
+// workspaceRow builds the header row of the workspace at root from its members.
+func (m model) workspaceRow(root string) table.Row {
+	members := m.workspaceMembers(root)
+
+	var size int64
+	var lastModified time.Time
+	staleness := int64(-1)
+	unsaved := false
+	busy := ""
+	selected := 0
+	name := filepath.Base(root)
+	var lock types.Reproducibility
+	var manager, engine string
+	for _, idx := range members {
+		module := m.modules[idx]
+		size += module.Size
+		if module.LastModified.After(lastModified) {
+			lastModified = module.LastModified
+		}
+		// The workspace is as stale as its most recently used member
+		if staleness < 0 || module.Staleness < staleness {
+			staleness = module.Staleness
+		}
+		unsaved = unsaved || module.GitDirty || module.GitUnpushed
+		if _, ok := m.deleting[module.Path]; ok {
+			busy = m.status[module.Path]
+		}
+		if m.selected[module.Path] {
+			selected++
+		}
+
+		if filepath.Dir(module.Path) == root {
+			if module.Project.Name != "" {
+				name = module.Project.Name
+			}
+			lock = module.Reproducibility
+			manager = module.Project.PackageManager
+			engine = module.Project.NodeEngine
+		}
+	}
+	if lock == "" && len(members) > 0 {
+		lock = m.modules[members[0]].Reproducibility
+	}
+
+	arrow := "▾"
+	if m.collapsed[root] {
+		arrow = "▸"
+	}
+	project := fmt.Sprintf("%s %s (%d)", arrow, name, len(members))
+	if unsaved {
+		project = "⚠ " + project
+	}
+	if busy != "" {
+		project = busy + " " + project
+	}
+
+	check := "[ ]"
+	switch {
+	case selected > 0 && selected == len(members):
+		check = "[x]"
+	case selected > 0:
+		check = "[-]"
+	}
+
+	return table.Row{
+		check,
+		project,
+		root,
+		utils.FormatSize(size),
+		lastModified.Format("2006-01-02 15:04:05"),
+		utils.ColorCodedStaleness(staleness),
+		reproducibilityStyle(lock).Render(string(lock)),
+		manager,
+		engine,
+	}
+}
@@ -0,0 +1,159 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/drxc00/sweepy/internal/clean"
+	"github.com/drxc00/sweepy/types"
+)
+
+// What each bulk action is called in the confirmation, and the row status while it runs
+var actionVerbs = map[string]string{
+	types.JournalModeRemove:  "Delete",
+	types.JournalModeTrash:   "Move to the trash",
+	types.JournalModeArchive: "Archive",
+}
+
+var actionDescriptions = map[string]string{
+	types.JournalModeRemove:  "delete permanently",
+	types.JournalModeTrash:   "move to the trash, restorable until it is emptied",
+	types.JournalModeArchive: "write a .tar.gz next to each project, then delete",
+}
+
+var actionStatus = map[string]string{
+	types.JournalModeRemove:  statusDeleting,
+	types.JournalModeTrash:   "[TRASHING...]",
+	types.JournalModeArchive: "[ARCHIVING...]",
+}
+
+// actionFor returns the bulk action bound to the key, "" when there is none.
+func (m model) actionFor(msg tea.KeyMsg) string {
+	switch {
+	case key.Matches(msg, m.keys.Delete):
+		return types.JournalModeRemove
+	case key.Matches(msg, m.keys.Trash):
+		return types.JournalModeTrash
+	case key.Matches(msg, m.keys.Archive):
+		return types.JournalModeArchive
+	}
+	return ""
+}
+
+// selectable tells whether the module can be selected, i.e. no action is running on it.
+func (m model) selectable(module types.ScannedNodeModule) bool {
+	_, busy := m.deleting[module.Path]
+	return !busy
+}
+
+// toggleRow selects or deselects the row under the cursor.
+// On a workspace header it applies to every member.
+func (m *model) toggleRow(row tableRow) {
+	if row.isHeader() {
+		m.toggleModules(m.workspaceMembers(row.workspace))
+		return
+	}
+	m.toggleModules([]int{row.module})
+}
+
+// toggleModules selects the modules at indexes, or deselects them when they all are selected.
+func (m *model) toggleModules(indexes []int) {
+	all := true
+	for _, idx := range indexes {
+		all = all && m.selected[m.modules[idx].Path]
+	}
+	for _, idx := range indexes {
+		if m.selectable(m.modules[idx]) {
+			m.selected[m.modules[idx].Path] = !all
+		}
+	}
+}
+
+// selectWhere replaces the selection with the modules for which keep returns true.
+// Modules hidden by the filter are never selected.
+func (m *model) selectWhere(keep func(module types.ScannedNodeModule) bool) {
+	for _, module := range m.modules {
+		m.selected[module.Path] = m.selectable(module) && m.matchesFilter(module) && keep(module)
+	}
+}
+
+// selectedModules returns the selected modules in table order.
+func (m model) selectedModules() []types.ScannedNodeModule {
+	var selected []types.ScannedNodeModule
+	for _, module := range m.modules {
+		if m.selected[module.Path] {
+			selected = append(selected, module)
+		}
+	}
+	return selected
+}
+
+// selectedSize sums the size of the selected modules.
+func (m model) selectedSize() int64 {
+	var size int64
+	for _, module := range m.selectedModules() {
+		size += module.Size
+	}
+	return size
+}
+
+// updateOlderInput handles the keys while the number of days for "select older than" is typed.
+func (m model) updateOlderInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key := msg.String(); key {
+	case "enter":
+		m.typingOlder = false
+		days, err := strconv.ParseInt(m.olderInput, 10, 64)
+		if err != nil {
+			m.notice = "Type a number of days."
+			return m, nil
+		}
+		m.selectWhere(func(module types.ScannedNodeModule) bool {
+			return module.Staleness >= days
+		})
+		m.notice = fmt.Sprintf("Selected %d directories not modified for %d days or more.", len(m.selectedModules()), days)
+		m.refreshRows()
+	case "esc":
+		m.typingOlder = false
+		m.notice = ""
+	case "backspace":
+		if len(m.olderInput) > 0 {
+			m.olderInput = m.olderInput[:len(m.olderInput)-1]
+		}
+		m.notice = "Select older than: " + m.olderInput + "_ days (enter to apply, esc to cancel)"
+	default:
+		if len(key) == 1 && key[0] >= '0' && key[0] <= '9' {
+			m.olderInput += key
+		}
+		m.notice = "Select older than: " + m.olderInput + "_ days (enter to apply, esc to cancel)"
+	}
+	return m, nil
+}
+
+// runAction applies mode to the selection with the batch engine.
+func (m model) runAction(mode string) (tea.Model, tea.Cmd) {
+	selected := m.selectedModules()
+	m.confirm, m.notice = nil, ""
+	m.deleteErr = nil
+
+	roots := m.ctx.Roots()
+	action := clean.RemoveAction(roots)
+	switch mode {
+	case types.JournalModeTrash:
+		action = clean.TrashAction(roots)
+	case types.JournalModeArchive:
+		action = clean.ArchiveAction(roots, "")
+	}
+
+	for _, module := range selected {
+		m.status[module.Path] = actionStatus[mode]
+		m.deleting[module.Path] = clean.BatchProgress{Path: module.Path}
+		m.batchTotal += module.Size
+		delete(m.selected, module.Path)
+	}
+	m.refreshRows()
+
+	// Perform the action as a command to avoid blocking the UI
+	return m, RunAction(selected, action, m.deleteChan)
+}
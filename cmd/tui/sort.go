@@ -0,0 +1,143 @@
+package tui
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/drxc00/sweepy/internal/config"
+)
+
+// Table column of each sort column, after the selection column
+var sortColumnIndex = map[string]int{
+	config.SortProject:   1,
+	config.SortPath:      2,
+	config.SortSize:      3,
+	config.SortModified:  4,
+	config.SortStaleness: 5,
+}
+
+// The direction a column starts in when s switches to it, biggest and stalest first
+var sortStartsDescending = map[string]bool{
+	config.SortSize:      true,
+	config.SortStaleness: true,
+}
+
+// sortKey holds what a row is sorted on. A workspace is sorted on the totals of its members.
+type sortKey struct {
+	project   string
+	path      string
+	size      int64
+	modified  time.Time
+	staleness int64
+}
+
+// moduleSortKey is the sort key of the module at idx.
+func (m model) moduleSortKey(idx int) sortKey {
+	module := m.modules[idx]
+	return sortKey{
+		project:   strings.ToLower(m.projectName(module)),
+		path:      module.Path,
+		size:      module.Size,
+		modified:  module.LastModified,
+		staleness: module.Staleness,
+	}
+}
+
+// workspaceSortKey is the sort key of the workspace at root, built like its header row.
+func (m model) workspaceSortKey(root string) sortKey {
+	key := sortKey{project: strings.ToLower(filepath.Base(root)), path: root, staleness: -1}
+	for _, idx := range m.workspaceMembers(root) {
+		module := m.modules[idx]
+		key.size += module.Size
+		if module.LastModified.After(key.modified) {
+			key.modified = module.LastModified
+		}
+		if key.staleness < 0 || module.Staleness < key.staleness {
+			key.staleness = module.Staleness
+		}
+		if filepath.Dir(module.Path) == root && module.Project.Name != "" {
+			key.project = strings.ToLower(module.Project.Name)
+		}
+	}
+	return key
+}
+
+// compareSortKeys orders a and b by the active sort, the path breaks ties so the order is stable.
+func (m model) compareSortKeys(a, b sortKey) int {
+	var c int
+	switch m.config.Sort.Column {
+	case config.SortSize:
+		c = cmp.Compare(a.size, b.size)
+	case config.SortStaleness:
+		c = cmp.Compare(a.staleness, b.staleness)
+	case config.SortModified:
+		c = a.modified.Compare(b.modified)
+	case config.SortProject:
+		c = cmp.Compare(a.project, b.project)
+	}
+	if c == 0 {
+		c = cmp.Compare(a.path, b.path)
+	}
+	if m.config.Sort.Descending {
+		return -c
+	}
+	return c
+}
+
+// sortModules orders module indexes by the active sort.
+func (m model) sortModules(indexes []int) {
+	slices.SortStableFunc(indexes, func(a, b int) int {
+		return m.compareSortKeys(m.moduleSortKey(a), m.moduleSortKey(b))
+	})
+}
+
+// cycleSort switches to the next sort column, or flips the direction when reverse is set.
+// The choice is saved as the default for the next runs.
+func (m *model) cycleSort(reverse bool) {
+	if reverse {
+		m.config.Sort.Descending = !m.config.Sort.Descending
+	} else {
+		next := (slices.Index(config.SortColumns, m.config.Sort.Column) + 1) % len(config.SortColumns)
+		m.config.Sort.Column = config.SortColumns[next]
+		m.config.Sort.Descending = sortStartsDescending[m.config.Sort.Column]
+	}
+
+	row, ok := m.selectedRow()
+	m.updateSortHeader()
+	m.refreshRows()
+	if ok {
+		m.moveToWorkspace(row)
+	}
+
+	direction := "ascending"
+	if m.config.Sort.Descending {
+		direction = "descending"
+	}
+	m.notice = "Sorted by " + m.config.Sort.Column + ", " + direction + "."
+	if err := config.SaveSort(m.config.Sort); err != nil {
+		m.notice += " Could not save it as the default: " + err.Error()
+	}
+}
+
+// updateSortHeader marks the sorted column with an arrow in the table header.
+func (m *model) updateSortHeader() {
+	columns := m.table.Columns()
+	for column, idx := range sortColumnIndex {
+		if idx >= len(columns) {
+			continue
+		}
+		title := strings.TrimRight(columns[idx].Title, " ▲▼")
+		if column == m.config.Sort.Column {
+			if m.config.Sort.Descending {
+				title += " ▼"
+			} else {
+				title += " ▲"
+			}
+		}
+		columns[idx].Title = title
+	}
+	m.table.SetColumns(columns)
+}
@@ -0,0 +1,122 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/drxc00/sweepy/internal/config"
+	"github.com/drxc00/sweepy/types"
+)
+
+func TestCompareSortKeys(t *testing.T) {
+	day := 24 * time.Hour
+	older := sortKey{project: "alpha", path: "/r/b", size: 10, modified: time.Unix(0, 0), staleness: 90}
+	newer := sortKey{project: "beta", path: "/r/a", size: 20, modified: time.Unix(0, 0).Add(day), staleness: 30}
+
+	tests := []struct {
+		name       string
+		sort       config.Sort
+		a, b       sortKey
+		expectSign int
+	}{
+		{name: "Size ascending", sort: config.Sort{Column: config.SortSize}, a: older, b: newer, expectSign: -1},
+		{name: "Size descending", sort: config.Sort{Column: config.SortSize, Descending: true}, a: older, b: newer, expectSign: 1},
+		{name: "Staleness", sort: config.Sort{Column: config.SortStaleness}, a: older, b: newer, expectSign: 1},
+		{name: "Modified", sort: config.Sort{Column: config.SortModified}, a: older, b: newer, expectSign: -1},
+		{name: "Project", sort: config.Sort{Column: config.SortProject}, a: older, b: newer, expectSign: -1},
+		{name: "Path", sort: config.Sort{Column: config.SortPath}, a: older, b: newer, expectSign: 1},
+		{name: "Tie broken by path", sort: config.Sort{Column: config.SortSize}, a: sortKey{path: "/r/a"}, b: sortKey{path: "/r/b"}, expectSign: -1},
+		{name: "Tie broken by path descending", sort: config.Sort{Column: config.SortSize, Descending: true}, a: sortKey{path: "/r/a"}, b: sortKey{path: "/r/b"}, expectSign: 1},
+		{name: "Equal", sort: config.Sort{Column: config.SortSize}, a: older, b: older, expectSign: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := model{config: config.Config{Sort: test.sort}}
+			if got := m.compareSortKeys(test.a, test.b); sign(got) != test.expectSign {
+				t.Errorf("Expected sign %d, but got %d", test.expectSign, got)
+			}
+		})
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+func TestBuildRows(t *testing.T) {
+	modules := []types.ScannedNodeModule{
+		{Path: "/r/small/node_modules", Size: 30, Staleness: 10},
+		{Path: "/r/ws/a/node_modules", Size: 5, Staleness: 50, Workspace: "/r/ws"},
+		{Path: "/r/big/node_modules", Size: 100, Staleness: 20},
+		{Path: "/r/ws/b/node_modules", Size: 50, Staleness: 40, Workspace: "/r/ws"},
+	}
+
+	tests := []struct {
+		name      string
+		sort      config.Sort
+		collapsed bool
+		filter    string
+		expected  []string // Paths, "ws:" and the root for a workspace header
+	}{
+		{
+			name:     "Size descending, a workspace by its total",
+			sort:     config.Sort{Column: config.SortSize, Descending: true},
+			expected: []string{"/r/big/node_modules", "ws:/r/ws", "/r/ws/b/node_modules", "/r/ws/a/node_modules", "/r/small/node_modules"},
+		},
+		{
+			name:     "Size ascending",
+			sort:     config.Sort{Column: config.SortSize},
+			expected: []string{"/r/small/node_modules", "ws:/r/ws", "/r/ws/a/node_modules", "/r/ws/b/node_modules", "/r/big/node_modules"},
+		},
+		{
+			name:     "Staleness, a workspace by its freshest member",
+			sort:     config.Sort{Column: config.SortStaleness},
+			expected: []string{"/r/small/node_modules", "/r/big/node_modules", "ws:/r/ws", "/r/ws/b/node_modules", "/r/ws/a/node_modules"},
+		},
+		{
+			name:      "Collapsed workspace",
+			sort:      config.Sort{Column: config.SortSize, Descending: true},
+			collapsed: true,
+			expected:  []string{"/r/big/node_modules", "ws:/r/ws", "/r/small/node_modules"},
+		},
+		{
+			name:     "Workspace with one visible member is a plain row",
+			sort:     config.Sort{Column: config.SortSize, Descending: true},
+			filter:   "<40B",
+			expected: []string{"/r/small/node_modules", "/r/ws/a/node_modules"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := model{
+				modules:   modules,
+				config:    config.Config{Sort: test.sort},
+				ctx:       types.ScanContext{Path: "/r"},
+				collapsed: map[string]bool{"/r/ws": test.collapsed},
+				filter:    test.filter,
+				rowFilter: parseFilter(test.filter),
+			}
+
+			var got []string
+			for _, row := range m.buildRows() {
+				if row.isHeader() {
+					got = append(got, "ws:"+row.workspace)
+					continue
+				}
+				got = append(got, modules[row.module].Path)
+			}
+			if !slices.Equal(got, test.expected) {
+				t.Errorf("Expected %v, but got %v", test.expected, got)
+			}
+		})
+	}
+}
@@ -1,78 +1,170 @@
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/drxc00/sweepy/internal/config"
+	"github.com/drxc00/sweepy/types"
+)
+
+// Colors of the theme in use, set by applyTheme
+var (
+	colorPrimary   lipgloss.TerminalColor
+	colorSecondary lipgloss.TerminalColor
+	colorBorder    lipgloss.TerminalColor
+	colorSelected  lipgloss.TerminalColor
+	colorError     lipgloss.TerminalColor
+	colorWarning   lipgloss.TerminalColor
+	colorHeader    lipgloss.TerminalColor
+	colorMuted     lipgloss.TerminalColor
+	colorAccent    lipgloss.TerminalColor
+	colorSuccess   lipgloss.TerminalColor
+	colorDeleting  lipgloss.TerminalColor
+
+	// Selected rows are shown in reverse video, for themes without a background color
+	reverseSelected bool
+)
 
 var (
-	colorPrimary   = lipgloss.Color("205") // Purple
-	colorSecondary = lipgloss.Color("99")  // Pink
-	colorBorder    = lipgloss.Color("240") // Gray
-	colorSelected  = lipgloss.Color("25")  // Green
-	colorError     = lipgloss.Color("9")   // Red
-	colorHeader    = lipgloss.Color("231") // White
+	titleStyle      lipgloss.Style
+	statsStyle      lipgloss.Style
+	errorStyle      lipgloss.Style
+	deleteErrStyle  lipgloss.Style
+	noticeStyle     lipgloss.Style
+	statsLabelStyle lipgloss.Style
+	statsValueStyle lipgloss.Style
+
+	// UI Styles for loading state
+	loadingTitleStyle   lipgloss.Style
+	loadingBoxStyle     lipgloss.Style
+	loadingPathStyle    lipgloss.Style
+	scanningLabelStyle  lipgloss.Style
+	scanningCountStyle  lipgloss.Style
+	scanningStatusStyle lipgloss.Style
+	loadingFooterStyle  lipgloss.Style
 
+	deletingStyle   lipgloss.Style
+	detailPaneStyle lipgloss.Style
+	helpBoxStyle    lipgloss.Style
+	confirmBoxStyle lipgloss.Style
+)
+
+func init() {
+	applyTheme(themes[config.ThemeDark], config.Default().StalenessThresholds)
+}
+
+// setStyles builds the styles from the colors of the theme.
+func setStyles() {
 	titleStyle = lipgloss.NewStyle().
-			Foreground(colorSecondary).
-			Bold(true).
-			Align(lipgloss.Center).
-			Padding(0, 2)
+		Foreground(colorSecondary).
+		Bold(true).
+		Align(lipgloss.Center).
+		Padding(0, 2)
 
 	statsStyle = lipgloss.NewStyle().
-			Padding(0, 2).
-			Align(lipgloss.Left)
+		Padding(0, 2).
+		Align(lipgloss.Left)
 
 	errorStyle = lipgloss.NewStyle().
-			Foreground(colorError).
-			Padding(1, 2)
+		Foreground(colorError).
+		Padding(1, 2)
+
+	deleteErrStyle = lipgloss.NewStyle().
+		Foreground(colorError).
+		Padding(0, 2)
+
+	noticeStyle = lipgloss.NewStyle().
+		Foreground(colorWarning).
+		Padding(0, 2)
 
 	// Add styles for stats text
 	statsLabelStyle = lipgloss.NewStyle().
-			Foreground(colorSecondary).
-			Bold(true)
+		Foreground(colorSecondary).
+		Bold(true)
 
 	statsValueStyle = lipgloss.NewStyle().
-			Foreground(colorPrimary)
-)
+		Foreground(colorPrimary)
 
-// UI Styles for loading state
-var (
 	loadingTitleStyle = lipgloss.NewStyle().
-				Foreground(colorPrimary).
-				Bold(true).
-				MarginBottom(1).
-				Align(lipgloss.Center)
+		Foreground(colorPrimary).
+		Bold(true).
+		MarginBottom(1).
+		Align(lipgloss.Center)
 
 	loadingBoxStyle = lipgloss.NewStyle().
-			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(colorSecondary).
-			Padding(1, 2).
-			Width(78)
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(colorSecondary).
+		Padding(1, 2).
+		Width(78)
 
 	loadingPathStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("240")).
-				Italic(true)
+		Foreground(colorMuted).
+		Italic(true)
 
 	scanningLabelStyle = lipgloss.NewStyle().
-				Foreground(colorPrimary).
-				Bold(true)
+		Foreground(colorPrimary).
+		Bold(true)
 
 	scanningCountStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("170")).
-				Bold(true)
+		Foreground(colorAccent).
+		Bold(true)
 
 	scanningStatusStyle = lipgloss.NewStyle().
-				Align(lipgloss.Center).
-				MarginTop(1).
-				MarginBottom(1)
+		Align(lipgloss.Center).
+		MarginTop(1).
+		MarginBottom(1)
 
 	loadingFooterStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("240")).
-				Align(lipgloss.Center).
-				MarginTop(1)
-)
+		Foreground(colorMuted).
+		Align(lipgloss.Center).
+		MarginTop(1)
 
-var (
-	// ... existing colors ...
-	colorDeleting = lipgloss.Color("#FFA500") // Orange
-	colorDeleted  = lipgloss.Color("#00FF00") // Green
-	colorFailed   = lipgloss.Color("#FF0000") // Red
-)
+	deletingStyle = lipgloss.NewStyle().
+		Foreground(colorDeleting)
+
+	detailPaneStyle = lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(colorBorder).
+		Padding(1, 1)
+
+	helpBoxStyle = lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(colorSecondary).
+		Padding(1, 2)
+
+	confirmBoxStyle = lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(colorError).
+		Padding(1, 2)
+}
+
+// reproducibilityStyle colors the LOCK column, risky deletions stand out.
+func reproducibilityStyle(r types.Reproducibility) lipgloss.Style {
+	switch r {
+	case types.ReproducibilityReproducible:
+		return lipgloss.NewStyle().Foreground(colorSuccess)
+	case types.ReproducibilityRisky:
+		return lipgloss.NewStyle().Foreground(colorError)
+	}
+	return lipgloss.NewStyle().Foreground(colorBorder)
+}
+
+// tableStyles is the look shared by every table.
+func tableStyles() table.Styles {
+	s := table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(colorSecondary).
+		BorderBottom(true).
+		Bold(true).
+		Foreground(colorHeader).
+		Background(colorBorder).
+		Padding(0, 1)
+
+	s.Selected = s.Selected.
+		Bold(true).
+		Background(colorSelected).
+		Foreground(colorPrimary).
+		Reverse(reverseSelected)
+	return s
+}
@@ -0,0 +1,134 @@
+package tui
+
+import (
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/drxc00/sweepy/internal/config"
+	"github.com/drxc00/sweepy/utils"
+)
+
+// theme is a set of colors by the names used in the config, see config.ColorNames.
+type theme struct {
+	colors  map[string]lipgloss.TerminalColor
+	reverse bool // Show the selected row in reverse video
+}
+
+var themes = map[string]theme{
+	config.ThemeDark: {colors: map[string]lipgloss.TerminalColor{
+		"primary":   lipgloss.Color("205"), // Purple
+		"secondary": lipgloss.Color("99"),  // Pink
+		"border":    lipgloss.Color("240"), // Gray
+		"selected":  lipgloss.Color("25"),  // Green
+		"error":     lipgloss.Color("9"),   // Red
+		"warning":   lipgloss.Color("11"),  // Yellow
+		"header":    lipgloss.Color("231"), // White
+		"muted":     lipgloss.Color("240"), // Gray
+		"accent":    lipgloss.Color("170"), // Magenta
+		"success":   lipgloss.Color("2"),   // Green
+		"deleting":  lipgloss.Color("#FFA500"),
+		"fresh":     lipgloss.Color("2"),  // Green
+		"stale":     lipgloss.Color("14"), // Cyan
+		"old":       lipgloss.Color("11"), // Yellow
+		"ancient":   lipgloss.Color("9"),  // Red
+	}},
+	// Darker shades that stay readable on a white background
+	config.ThemeLight: {colors: map[string]lipgloss.TerminalColor{
+		"primary":   lipgloss.Color("127"),
+		"secondary": lipgloss.Color("55"),
+		"border":    lipgloss.Color("250"),
+		"selected":  lipgloss.Color("153"),
+		"error":     lipgloss.Color("160"),
+		"warning":   lipgloss.Color("130"),
+		"header":    lipgloss.Color("16"),
+		"muted":     lipgloss.Color("244"),
+		"accent":    lipgloss.Color("91"),
+		"success":   lipgloss.Color("28"),
+		"deleting":  lipgloss.Color("166"),
+		"fresh":     lipgloss.Color("28"),
+		"stale":     lipgloss.Color("31"),
+		"old":       lipgloss.Color("130"),
+		"ancient":   lipgloss.Color("160"),
+	}},
+	// Only the 16 basic colors, at full brightness
+	config.ThemeHighContrast: {colors: map[string]lipgloss.TerminalColor{
+		"primary":   lipgloss.Color("15"),
+		"secondary": lipgloss.Color("14"),
+		"border":    lipgloss.Color("15"),
+		"selected":  lipgloss.Color("4"),
+		"error":     lipgloss.Color("9"),
+		"warning":   lipgloss.Color("11"),
+		"header":    lipgloss.Color("0"),
+		"muted":     lipgloss.Color("7"),
+		"accent":    lipgloss.Color("13"),
+		"success":   lipgloss.Color("10"),
+		"deleting":  lipgloss.Color("11"),
+		"fresh":     lipgloss.Color("10"),
+		"stale":     lipgloss.Color("14"),
+		"old":       lipgloss.Color("11"),
+		"ancient":   lipgloss.Color("9"),
+	}},
+	// No colors at all, the selected row is shown in reverse video
+	config.ThemeMonochrome: {colors: map[string]lipgloss.TerminalColor{}, reverse: true},
+}
+
+// noColor tells whether NO_COLOR is set, see https://no-color.org.
+func noColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// themeFor returns the theme of the config with its color overrides.
+// NO_COLOR wins over both.
+func themeFor(cfg config.Config) theme {
+	if noColor() {
+		return themes[config.ThemeMonochrome]
+	}
+
+	base, ok := themes[cfg.Theme]
+	if !ok {
+		base = themes[config.ThemeDark]
+	}
+	t := theme{colors: make(map[string]lipgloss.TerminalColor, len(base.colors)), reverse: base.reverse}
+	for name, color := range base.colors {
+		t.colors[name] = color
+	}
+	for name, color := range cfg.Colors {
+		t.colors[name] = lipgloss.Color(color)
+	}
+	return t
+}
+
+// applyTheme sets the colors, rebuilds the styles and sets the staleness thresholds.
+// It must run before the model is built, the spinner and the help take their styles then.
+func applyTheme(t theme, thresholds config.StalenessThresholds) {
+	color := func(name string) lipgloss.TerminalColor {
+		if c, ok := t.colors[name]; ok {
+			return c
+		}
+		return lipgloss.NoColor{}
+	}
+
+	colorPrimary = color("primary")
+	colorSecondary = color("secondary")
+	colorBorder = color("border")
+	colorSelected = color("selected")
+	colorError = color("error")
+	colorWarning = color("warning")
+	colorHeader = color("header")
+	colorMuted = color("muted")
+	colorAccent = color("accent")
+	colorSuccess = color("success")
+	colorDeleting = color("deleting")
+	reverseSelected = t.reverse
+	setStyles()
+
+	utils.SetStalenessScale(utils.StalenessScale{
+		Stale:        thresholds.Stale,
+		Old:          thresholds.Old,
+		Ancient:      thresholds.Ancient,
+		FreshColor:   color("fresh"),
+		StaleColor:   color("stale"),
+		OldColor:     color("old"),
+		AncientColor: color("ancient"),
+	})
+}
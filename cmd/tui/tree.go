@@ -0,0 +1,293 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/drxc00/sweepy/types"
+	"github.com/drxc00/sweepy/utils"
+)
+
+// treeNode is a directory between the scan root and the node_modules found under it,
+// or one of the node_modules themselves.
+type treeNode struct {
+	path     string
+	name     string
+	size     int64 // Of every node_modules under it
+	count    int   // node_modules under it
+	module   int   // Index in model.modules for a node_modules, -1 for a directory
+	depth    int
+	parent   *treeNode
+	children []*treeNode
+}
+
+// treeView shows the results as a directory tree, the biggest directories first, like ncdu.
+type treeView struct {
+	root     *treeNode
+	expanded map[string]bool
+	lines    []*treeNode // Visible nodes in table order
+	table    table.Model
+}
+
+// buildTree arranges the modules matching the filter under the scan root.
+func (m model) buildTree() *treeNode {
+	rootPath := filepath.Clean(m.ctx.Path)
+	root := &treeNode{path: rootPath, name: rootPath, module: -1}
+
+	for i, module := range m.modules {
+		if !m.matchesFilter(module) {
+			continue
+		}
+
+		// Reached through a symlink outside the root: hang it under the root by its full path
+		parts := []string{module.Path}
+		if rel, err := filepath.Rel(rootPath, module.Path); err == nil && filepath.IsLocal(rel) {
+			parts = strings.Split(rel, string(filepath.Separator))
+		}
+
+		node := root
+		node.size += module.Size
+		node.count++
+		for j, part := range parts {
+			idx := slices.IndexFunc(node.children, func(c *treeNode) bool { return c.name == part })
+			if idx < 0 {
+				child := &treeNode{
+					path:   filepath.Join(node.path, part),
+					name:   part,
+					module: -1,
+					depth:  node.depth + 1,
+					parent: node,
+				}
+				if len(parts) == 1 && parts[0] == module.Path {
+					child.path = module.Path
+				}
+				node.children = append(node.children, child)
+				idx = len(node.children) - 1
+			}
+			node = node.children[idx]
+			node.size += module.Size
+			node.count++
+			if j == len(parts)-1 {
+				node.module = i
+			}
+		}
+	}
+
+	sortTree(root)
+	return root
+}
+
+// sortTree orders every level by size, biggest first.
+func sortTree(node *treeNode) {
+	slices.SortFunc(node.children, func(a, b *treeNode) int {
+		if c := cmp.Compare(b.size, a.size); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.name, b.name)
+	})
+	for _, child := range node.children {
+		sortTree(child)
+	}
+}
+
+// modulesUnder returns the indexes of the node_modules in the subtree of node.
+func modulesUnder(node *treeNode) []int {
+	if node.module >= 0 {
+		return []int{node.module}
+	}
+	var modules []int
+	for _, child := range node.children {
+		modules = append(modules, modulesUnder(child)...)
+	}
+	return modules
+}
+
+// openTree switches to the tree view, with the first level open.
+func (m model) openTree() model {
+	m.tree = &treeView{expanded: map[string]bool{filepath.Clean(m.ctx.Path): true}}
+
+	t := table.New(
+		table.WithColumns(m.treeColumns()),
+		table.WithFocused(true),
+	)
+	t.SetStyles(tableStyles())
+	t.SetWidth(m.width - 6) // The height is set by layout
+	m.tree.table = t
+
+	m.refreshTree()
+	m.notice = ""
+	return m
+}
+
+func (m model) treeColumns() []table.Column {
+	return fitColumns(treeLayout, m.width-6)
+}
+
+// refreshTree rebuilds the tree from the modules, keeping the cursor on the same directory.
+func (m *model) refreshTree() {
+	tv := m.tree
+	current := ""
+	if cursor := tv.table.Cursor(); cursor >= 0 && cursor < len(tv.lines) {
+		current = tv.lines[cursor].path
+	}
+
+	tv.root = m.buildTree()
+	tv.lines = tv.lines[:0]
+	var walk func(node *treeNode)
+	walk = func(node *treeNode) {
+		tv.lines = append(tv.lines, node)
+		if !tv.expanded[node.path] {
+			return
+		}
+		for _, child := range node.children {
+			walk(child)
+		}
+	}
+	walk(tv.root)
+
+	rows := make([]table.Row, 0, len(tv.lines))
+	for _, node := range tv.lines {
+		rows = append(rows, m.treeRow(node))
+	}
+	tv.table.SetRows(rows)
+
+	if idx := slices.IndexFunc(tv.lines, func(n *treeNode) bool { return n.path == current }); idx >= 0 {
+		tv.table.SetCursor(idx)
+	} else if tv.table.Cursor() >= len(rows) && len(rows) > 0 {
+		tv.table.SetCursor(len(rows) - 1)
+	}
+}
+
+// treeRow builds the table row of a tree node.
+func (m model) treeRow(node *treeNode) table.Row {
+	modules := modulesUnder(node)
+	selected, busy := 0, ""
+	for _, idx := range modules {
+		path := m.modules[idx].Path
+		if m.selected[path] {
+			selected++
+		}
+		if _, ok := m.deleting[path]; ok {
+			busy = m.status[path]
+		} else if status, ok := m.status[path]; ok && node.module >= 0 {
+			busy = status
+		}
+	}
+
+	check := "[ ]"
+	switch {
+	case selected > 0 && selected == len(modules):
+		check = "[x]"
+	case selected > 0:
+		check = "[-]"
+	}
+
+	name := node.name
+	switch {
+	case node.module >= 0:
+		name = "  " + name
+	case m.tree.expanded[node.path]:
+		name = "▾ " + name
+	default:
+		name = "▸ " + name
+	}
+	name = strings.Repeat("  ", node.depth) + name
+	if busy != "" {
+		name = busy + " " + name
+	}
+
+	share := ""
+	if node.parent != nil {
+		share = fmt.Sprintf("%s %3d%%", utils.ProgressBar(node.size, node.parent.size, 10), utils.Percent(node.size, node.parent.size))
+	}
+
+	return table.Row{
+		check,
+		name,
+		utils.FormatSize(node.size),
+		share,
+		fmt.Sprintf("%d", node.count),
+	}
+}
+
+// selectedTreeNode returns the node under the cursor.
+func (m model) selectedTreeNode() (*treeNode, bool) {
+	cursor := m.tree.table.Cursor()
+	if cursor < 0 || cursor >= len(m.tree.lines) {
+		return nil, false
+	}
+	return m.tree.lines[cursor], true
+}
+
+// updateTree handles the keys that mean something else in the tree view.
+// The other keys, like quitting or filtering, work as in the list.
+func (m model) updateTree(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	tv := m.tree
+	node, ok := m.selectedTreeNode()
+
+	switch {
+	case key.Matches(msg, m.keys.Tree, m.keys.Back):
+		m.tree = nil
+		m.notice = ""
+		m.resizeList() // The detail pane comes back with the list
+	case key.Matches(msg, m.keys.Up):
+		tv.table.MoveUp(1)
+		m.notice = ""
+	case key.Matches(msg, m.keys.Down):
+		tv.table.MoveDown(1)
+		m.notice = ""
+	case key.Matches(msg, m.keys.Open, m.keys.Expand):
+		if ok && node.module < 0 {
+			tv.expanded[node.path] = key.Matches(msg, m.keys.Expand) || !tv.expanded[node.path]
+			m.refreshTree()
+		}
+	case key.Matches(msg, m.keys.Collapse):
+		// Close the directory, or go up to its parent when it is already closed
+		if !ok {
+			break
+		}
+		if node.module < 0 && tv.expanded[node.path] && node.parent != nil {
+			tv.expanded[node.path] = false
+			m.refreshTree()
+		} else if node.parent != nil {
+			tv.table.SetCursor(slices.Index(tv.lines, node.parent))
+		}
+	case key.Matches(msg, m.keys.Select):
+		if ok {
+			m.toggleModules(modulesUnder(node))
+			m.refreshRows()
+		}
+	case key.Matches(msg, m.keys.Delete, m.keys.Trash, m.keys.Archive):
+		// Everything under the directory is selected, the confirmation lists it
+		if !ok {
+			return m, nil, true
+		}
+		// The selection made in the list comes back once the dialog is closed
+		under := make(map[string]bool)
+		for _, idx := range modulesUnder(node) {
+			under[m.modules[idx].Path] = true
+		}
+		prior := maps.Clone(m.selected)
+		m.selectWhere(func(module types.ScannedNodeModule) bool { return under[module.Path] })
+		subtree := m.selectedModules()
+		m.refreshRows()
+		next, cmd := m.requestAction(m.actionFor(msg))
+		nm := next.(model)
+		if nm.confirm != nil {
+			nm.confirm.prior = prior
+		} else {
+			nm.restoreSelection(prior, subtree)
+		}
+		return nm, cmd, true
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
@@ -0,0 +1,121 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/drxc00/sweepy/types"
+)
+
+// flattenTree lists the nodes depth first as "name size count", indented by depth,
+// with a * on the node_modules themselves.
+func flattenTree(node *treeNode) []string {
+	line := fmt.Sprintf("%s%s %d %d", strings.Repeat("  ", node.depth), node.name, node.size, node.count)
+	if node.module >= 0 {
+		line += " *"
+	}
+	lines := []string{line}
+	for _, child := range node.children {
+		lines = append(lines, flattenTree(child)...)
+	}
+	return lines
+}
+
+func TestBuildTree(t *testing.T) {
+	root := filepath.FromSlash("/r")
+	outside := filepath.FromSlash("/elsewhere/node_modules")
+	modules := []types.ScannedNodeModule{
+		{Path: filepath.Join(root, "a", "x", "node_modules"), Size: 10},
+		{Path: filepath.Join(root, "b", "node_modules"), Size: 50},
+		{Path: filepath.Join(root, "a", "y", "node_modules"), Size: 20},
+		{Path: outside, Size: 5}, // Reached through a symlink
+	}
+
+	tests := []struct {
+		name     string
+		filter   string
+		expected []string
+		under    map[string][]int // Modules under the node at each path
+	}{
+		{
+			name: "Biggest first",
+			expected: []string{
+				root + " 85 4",
+				"  b 50 1",
+				"    node_modules 50 1 *",
+				"  a 30 2",
+				"    y 20 1",
+				"      node_modules 20 1 *",
+				"    x 10 1",
+				"      node_modules 10 1 *",
+				"  " + outside + " 5 1 *",
+			},
+			under: map[string][]int{
+				root:                     {0, 1, 2, 3},
+				filepath.Join(root, "a"): {0, 2},
+				filepath.Join(root, "b"): {1},
+				outside:                  {3},
+			},
+		},
+		{
+			name:   "Filtered",
+			filter: ">15B",
+			expected: []string{
+				root + " 70 2",
+				"  b 50 1",
+				"    node_modules 50 1 *",
+				"  a 20 1",
+				"    y 20 1",
+				"      node_modules 20 1 *",
+			},
+			under: map[string][]int{
+				root:                     {1, 2},
+				filepath.Join(root, "a"): {2},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := model{
+				modules:   modules,
+				ctx:       types.ScanContext{Path: root},
+				filter:    test.filter,
+				rowFilter: parseFilter(test.filter),
+			}
+			tree := m.buildTree()
+
+			if got := flattenTree(tree); !slices.Equal(got, test.expected) {
+				t.Errorf("Expected tree\n%s\nbut got\n%s", strings.Join(test.expected, "\n"), strings.Join(got, "\n"))
+			}
+
+			for path, expected := range test.under {
+				node := findNode(tree, path)
+				if node == nil {
+					t.Errorf("Expected a node at %s", path)
+					continue
+				}
+				got := modulesUnder(node)
+				slices.Sort(got)
+				if !slices.Equal(got, expected) {
+					t.Errorf("Expected modules %v under %s, but got %v", expected, path, got)
+				}
+			}
+		})
+	}
+}
+
+func findNode(node *treeNode, path string) *treeNode {
+	if node.path == path {
+		return node
+	}
+	for _, child := range node.children {
+		if found := findNode(child, path); found != nil {
+			return found
+		}
+	}
+	return nil
+}
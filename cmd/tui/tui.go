@@ -6,14 +6,24 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/drxc00/sweepy/internal/clean"
+	"github.com/drxc00/sweepy/internal/config"
 	"github.com/drxc00/sweepy/types"
 	"github.com/drxc00/sweepy/utils"
 )
 
+// Row prefixes while a deletion is running or after it failed
+const (
+	statusDeleting = "[DELETING...]"
+	statusFailed   = "[FAILED]"
+)
+
 // --- Model ---
 
 type model struct {
@@ -23,8 +33,17 @@ type model struct {
 	scanComplete bool
 	modules      []types.ScannedNodeModule
 
+	// What each table row shows, workspace members are grouped under a header
+	rows      []tableRow
+	collapsed map[string]bool // Workspace roots whose members are hidden
+
 	// Config
-	ctx types.ScanContext
+	ctx       types.ScanContext
+	config    config.Config
+	noConfirm bool // Bulk actions run without the confirmation
+	keys      keyMap
+	help      help.Model
+	showHelp  bool // The ? overlay listing every binding
 
 	// Verbose
 	progressChan  chan string
@@ -37,14 +56,52 @@ type model struct {
 	avgStaleness  float64
 	scanDuration  string
 
-	// deleted
-	deletedPaths []string
-	beingDeleted []string
+	// [DELETING...] or [FAILED] per node_modules path
+	status map[string]string
+
+	// Deletions in flight, shared by every batch
+	deleteChan chan clean.BatchProgress
+	deleting   map[string]clean.BatchProgress
+	batchBytes int64 // Removed so far by the running deletions
+	batchTotal int64 // Size of everything being deleted, reset once all are done
+
+	// Last deletion error, shown above the footer
+	deleteErr error
+
+	// Packages of the node_modules opened with enter, nil when the list is shown
+	breakdown *breakdownView
+
+	// Directory tree opened with v, nil when the list is shown
+	tree *treeView
+
+	// A rescan started with r or R is running, the current results stay on screen
+	rescanning bool
+
+	// Detail pane toggled with p, the details are read when the cursor rests on a row
+	showDetails bool
+	details     map[string]*detailEntry
+
+	// Selected node_modules paths, the bulk actions apply to them
+	selected map[string]bool
+
+	// Bulk action waiting for confirmation, nil when none
+	confirm *confirmDialog
+
+	// Days typed after o, to select everything older
+	typingOlder bool
+	olderInput  string
+
+	// Content of the filter bar opened with /, rows not matching it are hidden
+	typingFilter bool
+	filter       string
+	rowFilter    rowFilter // filter parsed
+
+	notice string
 }
 
 // --- Init Functions ---
 
-func initialModel(ctx types.ScanContext) model {
+func initialModel(ctx types.ScanContext, cfg config.Config, noConfirm bool) model {
 	s := spinner.New()
 	s.Spinner = spinner.MiniDot
 	s.Style = lipgloss.NewStyle().Foreground(colorPrimary).Bold(true)
@@ -53,8 +110,18 @@ func initialModel(ctx types.ScanContext) model {
 		isLoading:    true,
 		scanComplete: false,
 		ctx:          ctx,
+		config:       cfg,
+		keys:         newKeyMap(cfg.Keys),
+		help:         newHelp(),
+		noConfirm:    noConfirm,
 		progressChan: make(chan string, 1000),
 		lastUpdated:  time.Now(),
+		collapsed:    make(map[string]bool),
+		status:       make(map[string]string),
+		deleteChan:   make(chan clean.BatchProgress, 100),
+		deleting:     make(map[string]clean.BatchProgress),
+		selected:     make(map[string]bool),
+		details:      make(map[string]*detailEntry),
 	}
 }
 
@@ -68,17 +135,7 @@ type scanProgressMsg struct {
 	path string
 }
 
-type deleteSuccessMsg struct {
-	path  string
-	index int
-	size  int64
-}
-
-type deleteErrMsg struct {
-	err   error
-	path  string
-	index int
-}
+type deleteProgressMsg clean.BatchProgress
 
 // --- BubbleTea Handlers ---
 
@@ -86,65 +143,150 @@ func (m model) Init() tea.Cmd {
 	return tea.Batch(
 		m.spinner.Tick,
 		StartScan(m.ctx, m.progressChan),
+		ListenForDeletes(m.deleteChan),
 	)
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if nm, ok := next.(model); ok {
+		nm.layout()
+		next = nm
+	}
+
+	// Any key may have moved the cursor to a row whose details are not read yet
+	if _, ok := msg.(tea.KeyMsg); ok {
+		if nm, ok := next.(model); ok {
+			return nm, tea.Batch(cmd, nm.watchDetails())
+		}
+	}
+	return next, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		if m.breakdown != nil {
+			return m.updateBreakdown(msg)
+		}
+
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		if m.typingOlder {
+			return m.updateOlderInput(msg)
+		}
+		if m.typingFilter {
+			return m.updateFilterInput(msg)
+		}
+		if m.confirm != nil {
+			return m.updateConfirm(msg)
+		}
+		if m.showHelp {
+			// Any key closes the help, only quitting goes through
+			m.showHelp = false
+			if key.Matches(msg, m.keys.Quit) {
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+		if m.tree != nil {
+			if next, cmd, handled := m.updateTree(msg); handled {
+				return next, cmd
+			}
+		}
+
+		switch {
+		case key.Matches(msg, m.keys.Quit):
 			return m, tea.Quit
-		case "up":
+		case key.Matches(msg, m.keys.Help):
+			m.showHelp = true
+		case key.Matches(msg, m.keys.Up):
 			m.table.MoveUp(1)
-		case "down":
+			m.notice = ""
+		case key.Matches(msg, m.keys.Down):
 			m.table.MoveDown(1)
-		case " ":
-			if m.scanComplete && len(m.table.Rows()) > 0 {
-				selectedIndex := m.table.Cursor()
-				if selectedIndex >= 0 && selectedIndex < len(m.table.Rows()) {
-					currentRows := m.table.Rows()
-					selectedPath := currentRows[selectedIndex][1] // Assuming the second column contains the paths
-					if len(currentRows[selectedIndex]) > 0 && selectedPath != "" && !slices.Contains(m.deletedPaths, selectedPath) && !slices.Contains(m.beingDeleted, selectedPath) {
-						// Add to the list of paths being deleted
-						m.beingDeleted = append(m.beingDeleted, selectedPath)
-
-						selectedModule := m.modules[selectedIndex]
-
-						// Immediately update the UI to show "Deleting..."
-						updatedRow := make(table.Row, len(currentRows[selectedIndex]))
-						copy(updatedRow, currentRows[selectedIndex])
-						updatedRow[0] = "[DELETING...] " + strings.Replace(updatedRow[0], "[FAILED] ", "", 1) // Modify the first column
-
-						newRows := make([]table.Row, len(currentRows))
-						copy(newRows, currentRows)
-						newRows[selectedIndex] = updatedRow
-						m.table.SetRows(newRows)
-
-						// Perform the deletion as a command to avoid blocking the UI
-						cmd := func() tea.Msg {
-							defer func() {
-								// Safely remove from beingDeleted slice only if the path exists
-								idx := slices.Index(m.beingDeleted, selectedPath)
-								if idx >= 0 {
-									m.beingDeleted = slices.Delete(m.beingDeleted, idx, idx+1)
-								}
-							}()
-							return DeleteNode(selectedModule, selectedIndex)
-						}
-						return m, cmd
-					}
-				}
+			m.notice = ""
+		case key.Matches(msg, m.keys.Rescan, m.keys.RescanNoCache):
+			return m.rescan(key.Matches(msg, m.keys.RescanNoCache))
+		case key.Matches(msg, m.keys.Details):
+			if m.scanComplete {
+				return m.toggleDetails()
 			}
+		case key.Matches(msg, m.keys.Open):
+			row, ok := m.selectedRow()
+			if !ok {
+				return m, nil
+			}
+			if !row.isHeader() {
+				return m.openBreakdown(m.modules[row.module])
+			}
+			m.collapsed[row.workspace] = !m.collapsed[row.workspace]
+			m.refreshRows()
+			m.notice = ""
+		case key.Matches(msg, m.keys.Expand, m.keys.Collapse):
+			if row, ok := m.selectedRow(); ok && row.workspace != "" {
+				m.collapsed[row.workspace] = key.Matches(msg, m.keys.Collapse)
+				m.refreshRows()
+				m.moveToWorkspace(row)
+				m.notice = ""
+			}
+		case key.Matches(msg, m.keys.Select):
+			if row, ok := m.selectedRow(); ok {
+				m.toggleRow(row)
+				m.refreshRows()
+			}
+		case key.Matches(msg, m.keys.SelectAll):
+			m.selectWhere(func(types.ScannedNodeModule) bool { return true })
+			m.refreshRows()
+		case key.Matches(msg, m.keys.SelectNone):
+			m.selectWhere(func(types.ScannedNodeModule) bool { return false })
+			m.refreshRows()
+		case key.Matches(msg, m.keys.Invert):
+			selected := m.selected
+			m.selected = make(map[string]bool)
+			m.selectWhere(func(module types.ScannedNodeModule) bool { return !selected[module.Path] })
+			m.refreshRows()
+		case key.Matches(msg, m.keys.OlderThan):
+			if m.scanComplete {
+				m.typingOlder, m.olderInput = true, ""
+				m.notice = "Select older than: _ days (enter to apply, esc to cancel)"
+			}
+		case key.Matches(msg, m.keys.Filter):
+			if m.scanComplete {
+				m.typingFilter = true
+				m.notice = ""
+			}
+		case key.Matches(msg, m.keys.Sort, m.keys.Reverse):
+			if m.scanComplete {
+				m.cycleSort(key.Matches(msg, m.keys.Reverse))
+			}
+		case key.Matches(msg, m.keys.Tree):
+			if m.scanComplete {
+				return m.openTree(), nil
+			}
+		case key.Matches(msg, m.keys.Delete, m.keys.Trash, m.keys.Archive):
+			return m.requestAction(m.actionFor(msg))
 		}
 	case tea.WindowSizeMsg:
+		// The heights follow in layout, after every message
 		m.width, m.height = msg.Width, msg.Height
+		m.help.Width = m.width - 4
 		if m.scanComplete {
-			// Allow the table to use most of the available width
-			m.table.SetWidth(m.width - 4)
-			m.table.SetHeight(m.height - 12) // Give more space for stats and footer
+			m.resizeList()
+		}
+		if m.breakdown != nil && !m.breakdown.loading {
+			m.breakdown.table.SetColumns(fitColumns(breakdownLayout, m.width-6))
+			m.breakdown.table.SetWidth(m.width - 6)
+		}
+		if m.tree != nil {
+			m.tree.table.SetColumns(m.treeColumns())
+			m.tree.table.SetWidth(m.width - 6)
 		}
 	case scanResultMsg:
+		if m.scanComplete {
+			return m.applyRescan(msg), nil
+		}
 		m.isLoading = false
 		m.scanComplete = true
 		m.modules = msg.modules
@@ -158,71 +300,26 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		}
 
-		// Calculate proportional column widths based on content
-		availableWidth := m.width - 8 // Allow for some padding and borders
-
-		// Define column ratios (proportions of total width)
-		projectRatio := 0.15
-		pathRatio := 0.40
-		sizeRatio := 0.10
-		modifiedRatio := 0.15
-		stalenessRatio := 0.15
-
-		// Apply ratios to calculate actual column widths
-		projectWidth := int(float64(availableWidth) * projectRatio)
-		pathWidth := int(float64(availableWidth) * pathRatio)
-		sizeWidth := int(float64(availableWidth) * sizeRatio)
-		modifiedWidth := int(float64(availableWidth) * modifiedRatio)
-		stalenessWidth := int(float64(availableWidth) * stalenessRatio)
-
-		columns := []table.Column{
-			{Title: "PROJECT", Width: projectWidth},
-			{Title: "PATH", Width: pathWidth},
-			{Title: "SIZE", Width: sizeWidth},
-			{Title: "LAST MODIFIED", Width: modifiedWidth},
-			{Title: "STALENESS", Width: stalenessWidth},
-		}
-
-		var rows []table.Row
+		// Workspaces start collapsed, their members are one enter away
 		for _, module := range m.modules {
-			rows = append(rows, table.Row{
-				utils.FormatPath(module.Path, m.ctx.Path),
-				module.Path,
-				utils.FormatSize(module.Size),
-				module.LastModified.Format("2006-01-02 15:04:05"),
-				utils.ColorCodedStaleness(module.Staleness),
-			})
+			if module.Workspace != "" {
+				m.collapsed[module.Workspace] = true
+			}
 		}
 
 		t := table.New(
-			table.WithColumns(columns),
-			table.WithRows(rows),
+			table.WithColumns(m.listColumns()),
 			table.WithFocused(true),
-			table.WithHeight(m.height-20),
 		)
 
-		s := table.DefaultStyles()
-		s.Header = s.Header.
-			BorderStyle(lipgloss.NormalBorder()).
-			BorderForeground(colorSecondary).
-			BorderBottom(true).
-			Bold(true).
-			Foreground(colorHeader).
-			Background(colorBorder).
-			Padding(0, 1)
-
-		s.Selected = s.Selected.
-			Bold(true).
-			Background(colorSelected).
-			Foreground(colorPrimary)
-
-		t.SetStyles(s)
+		t.SetStyles(tableStyles())
 
-		// Adjust table dimensions to account for borders and padding
-		t.SetHeight(m.height - 14)
-		t.SetWidth(m.width - 6)
+		// Adjust table dimensions to account for borders and padding, the height is set by layout
+		t.SetWidth(m.listWidth() - 6)
 
 		m.table = t
+		m.updateSortHeader()
+		m.refreshRows()
 		return m, nil
 	case spinner.TickMsg:
 		var cmd tea.Cmd
@@ -232,67 +329,125 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.scanningPaths = append(m.scanningPaths, msg.path)
 		m.lastUpdated = time.Now()
 		return m, ListenForProgress(m.progressChan)
-	case deleteSuccessMsg:
-		// Update the UI to show "DELETED" and remove the module from the model
-		if msg.index >= 0 && msg.index < len(m.table.Rows()) {
-			currentRows := m.table.Rows()
-			if len(currentRows[msg.index]) > 0 {
-				updatedRow := make(table.Row, len(currentRows[msg.index]))
-				copy(updatedRow, currentRows[msg.index])
-				updatedRow[0] = "[DELETED] " + strings.Replace(updatedRow[0], "[DELETING...] ", "", 1) // Modify the first column to indicate deletion
-				newRows := make([]table.Row, len(currentRows))
-				copy(newRows, currentRows)
-				newRows[msg.index] = updatedRow
-				m.table.SetRows(newRows)
-
-				// Update the model
-				if msg.index < len(m.modules) {
-					m.totalSize -= m.modules[msg.index].Size
-					// m.modules = append(m.modules[:msg.index], m.modules[msg.index+1:]...)
-					m.modules = slices.Delete(m.modules, msg.index, msg.index+1)
-
-					// Remove the deleted row from the table (optional, but might be desired)
-					currentTableRows := m.table.Rows()
-					if msg.index < len(currentTableRows) {
-						r := slices.Delete(currentTableRows, msg.index, msg.index+1)
-						// m.table.SetRows(append(currentTableRows[:msg.index], currentTableRows[msg.index+1:]...))
-						m.table.SetRows(r)
-
-						// Adjust cursor if necessary
-						if m.table.Cursor() >= len(m.table.Rows()) && len(m.table.Rows()) > 0 {
-							m.table.SetCursor(len(m.table.Rows()) - 1)
-						}
-					}
-				}
+	case detailRestMsg:
+		return m.startDetails(msg)
+	case detailsMsg:
+		m.details[msg.path] = &detailEntry{details: msg.details, err: msg.err}
+		return m, nil
+	case breakdownMsg:
+		m = m.setBreakdown(msg)
+		return m, nil
+	case unsavedMsg:
+		return m.setUnsaved(msg)
+	case deleteProgressMsg:
+		prev := m.deleting[msg.Path]
+		m.batchBytes += msg.Bytes - prev.Bytes
+		if !msg.Done {
+			m.deleting[msg.Path] = clean.BatchProgress(msg)
+			m.refreshRows()
+			return m, ListenForDeletes(m.deleteChan)
+		}
 
-				m.deletedPaths = append(m.deletedPaths, msg.path)
-			}
+		delete(m.deleting, msg.Path)
+		if len(m.deleting) == 0 {
+			m.batchBytes, m.batchTotal = 0, 0
 		}
-		return m, nil
 
-	case deleteErrMsg:
-		// Handle the error message.
-		// Indicate that the deletion failed with [FAILED]
-
-		if msg.index >= 0 && msg.index < len(m.table.Rows()) {
-			currentRows := m.table.Rows()
-			if len(currentRows[msg.index]) > 0 {
-				updatedRow := make(table.Row, len(currentRows[msg.index]))
-				// Remove the "[DELETING...]" prefix
-
-				copy(updatedRow, currentRows[msg.index])
-				updatedRow[0] = "[FAILED] " + strings.Replace(updatedRow[0], "[DELETING...] ", "", 1)
-				newRows := make([]table.Row, len(currentRows))
-				copy(newRows, currentRows)
-				newRows[msg.index] = updatedRow
-				m.table.SetRows(newRows)
+		if msg.Err != nil {
+			// Indicate that the deletion failed with [FAILED]
+			m.status[msg.Path] = statusFailed
+			m.deleteErr = msg.Err
+		} else {
+			// Drop the module, its row goes away with it
+			delete(m.status, msg.Path)
+			idx := slices.IndexFunc(m.modules, func(module types.ScannedNodeModule) bool {
+				return module.Path == msg.Path
+			})
+			if idx >= 0 {
+				m.modules = slices.Delete(m.modules, idx, idx+1)
+				m.totalSize, m.avgStaleness = moduleTotals(m.modules)
 			}
 		}
+		m.refreshRows()
+		return m, ListenForDeletes(m.deleteChan)
 	}
 
 	return m, nil
 }
 
+// selectedRow returns the row under the cursor.
+func (m model) selectedRow() (tableRow, bool) {
+	if !m.scanComplete {
+		return tableRow{}, false
+	}
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.rows) {
+		return tableRow{}, false
+	}
+	return m.rows[cursor], true
+}
+
+// moveToWorkspace keeps the cursor on row after the rows were rebuilt,
+// or on its workspace header when row is now hidden.
+func (m *model) moveToWorkspace(row tableRow) {
+	for i, r := range m.rows {
+		if r == row {
+			m.table.SetCursor(i)
+			return
+		}
+	}
+	for i, r := range m.rows {
+		if r.isHeader() && r.workspace == row.workspace {
+			m.table.SetCursor(i)
+			return
+		}
+	}
+}
+
+// moduleRow builds the table row for a module.
+func (m model) moduleRow(module types.ScannedNodeModule) table.Row {
+	project := m.projectName(module)
+	if module.GitDirty || module.GitUnpushed {
+		project = "⚠ " + project // Uncommitted or unpushed work
+	}
+	if progress, ok := m.deleting[module.Path]; ok && m.status[module.Path] == statusDeleting && module.Size > 0 {
+		project = fmt.Sprintf("[DELETING %d%%] %s", utils.Percent(progress.Bytes, module.Size), project)
+	} else if status, ok := m.status[module.Path]; ok {
+		project = status + " " + project
+	}
+
+	size := utils.FormatSize(module.Size)
+	if module.IsSymlink {
+		size = "symlink"
+	}
+
+	check := "[ ]"
+	if m.selected[module.Path] {
+		check = "[x]"
+	}
+
+	return table.Row{
+		check,
+		project,
+		module.Path,
+		size,
+		module.LastModified.Format("2006-01-02 15:04:05"),
+		utils.ColorCodedStaleness(module.Staleness),
+		reproducibilityStyle(module.Reproducibility).Render(string(module.Reproducibility)),
+		module.Project.PackageManager,
+		module.Project.NodeEngine,
+	}
+}
+
+// projectName prefers the name from package.json over the directory name,
+// dozens of projects are called "frontend" or "app" on disk.
+func (m model) projectName(module types.ScannedNodeModule) string {
+	if module.Project.Name != "" {
+		return module.Project.Name
+	}
+	return utils.FormatPath(module.Path, m.ctx.Path)
+}
+
 // Update the View method's loading section
 func (m model) View() string {
 	if m.err != nil {
@@ -302,6 +457,18 @@ func (m model) View() string {
 		))
 	}
 
+	if m.breakdown != nil {
+		return m.breakdownContent()
+	}
+
+	if m.confirm != nil {
+		return m.confirmContent()
+	}
+
+	if m.showHelp {
+		return m.helpContent()
+	}
+
 	if m.isLoading {
 		var b strings.Builder
 
@@ -321,14 +488,18 @@ func (m model) View() string {
 		b.WriteString(status)
 		b.WriteString("\n\n")
 
-		// Progress box with enhanced styling for paths
-		if len(m.scanningPaths) > 0 {
+		// Progress box with enhanced styling for paths, as many as the height leaves room for
+		recent := 6
+		if m.height > 0 {
+			recent = min(recent, m.height-17)
+		}
+		if len(m.scanningPaths) > 0 && recent > 0 {
 			var pathsContent strings.Builder
 
 			// Show latest paths, most recent at bottom
 			start := 0
-			if len(m.scanningPaths) > 6 {
-				start = len(m.scanningPaths) - 6
+			if len(m.scanningPaths) > recent {
+				start = len(m.scanningPaths) - recent
 			}
 
 			// Add a header
@@ -337,59 +508,150 @@ func (m model) View() string {
 				Underline(true).
 				Render("Recently scanned paths:") + "\n\n")
 
+			// The box shrinks with the terminal
+			boxWidth := 78
+			if m.width > 0 {
+				boxWidth = min(boxWidth, m.width-2)
+			}
+			maxPath := max(boxWidth-8, 10)
 			for _, path := range m.scanningPaths[start:] {
 				// Truncate long paths with ellipsis
-				if len(path) > 70 {
-					path = "..." + path[len(path)-67:]
+				if len(path) > maxPath {
+					path = "..." + path[len(path)-(maxPath-3):]
 				}
 				pathsContent.WriteString(loadingPathStyle.Render(path))
 				pathsContent.WriteString("\n")
 			}
 
 			// Add the progress box to the view
-			b.WriteString(loadingBoxStyle.Render(pathsContent.String()))
+			b.WriteString(loadingBoxStyle.Width(boxWidth).Render(pathsContent.String()))
 			b.WriteString("\n")
 		}
 
 		// Footer with keybindings
-		b.WriteString(loadingFooterStyle.Render("Press q or Ctrl+C to quit"))
+		b.WriteString(loadingFooterStyle.Render("Press " + m.keys.Quit.Help().Key + " or Ctrl+C to quit"))
 
 		return b.String()
 	}
 
+	// Table with border
+	tableBorder := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(colorBorder).
+		Padding(1, 1)
+
+	tableView := m.withDetailPane(tableBorder.Render(m.table.View()))
+	if m.tree != nil {
+		tableView = tableBorder.Render(m.tree.table.View())
+	}
+	return m.headerView() + tableView + m.footerView()
+}
+
+// moduleTotals returns the total size and the average staleness of modules.
+func moduleTotals(modules []types.ScannedNodeModule) (int64, float64) {
+	var size int64
+	var staleness float64
+	for _, module := range modules {
+		size += module.Size
+		staleness += float64(module.Staleness)
+	}
+	if len(modules) > 0 {
+		staleness /= float64(len(modules))
+	}
+	return size, staleness
+}
+
+// headerView renders the title and the stats above the table.
+// A short terminal gets them on one line.
+func (m model) headerView() string {
 	var b strings.Builder
 
 	// Title with improved styling
-	b.WriteString("\n")
+	if !m.compact() {
+		b.WriteString("\n")
+	}
 	b.WriteString(titleStyle.Render("📦 SWEEPY 📦"))
 	b.WriteString("\n\n")
 
+	// With a filter the totals are those of the visible rows
+	count := fmt.Sprintf("%d", len(m.modules))
+	totalSize, avgStaleness := m.totalSize, m.avgStaleness
+	if m.filter != "" {
+		visible := m.visibleModules()
+		count = fmt.Sprintf("%d of %d", len(visible), len(m.modules))
+		totalSize, avgStaleness = moduleTotals(visible)
+	}
+
+	if m.compact() {
+		stats := fmt.Sprintf(
+			"%s %s  %s %s  %s %s",
+			statsLabelStyle.Render("Found:"),
+			statsValueStyle.Render(count),
+			statsLabelStyle.Render("Size:"),
+			statsValueStyle.Render(utils.FormatSize(totalSize)),
+			statsLabelStyle.Render("Selected:"),
+			statsValueStyle.Render(fmt.Sprintf("%d (%s)", len(m.selectedModules()), utils.FormatSize(m.selectedSize()))),
+		)
+		b.WriteString(statsStyle.MaxWidth(m.width).Render(stats))
+		b.WriteString("\n")
+		return b.String()
+	}
+
 	// Stats with improved formatting
 	stats := fmt.Sprintf(
-		"%s %s\n%s %s\n%s %s\n%s %s\n",
+		"%s %s\n%s %s\n%s %s\n%s %s\n%s %s\n",
 		statsLabelStyle.Render("Found:"),
-		statsValueStyle.Render(fmt.Sprintf("%d node_modules directories", len(m.modules))),
+		statsValueStyle.Render(count+" node_modules directories"),
 		statsLabelStyle.Render("Total Size:"),
-		statsValueStyle.Render(fmt.Sprintf("%.2f MB", float64(m.totalSize)/1024/1024)),
+		statsValueStyle.Render(fmt.Sprintf("%.2f MB", float64(totalSize)/1024/1024)),
 		statsLabelStyle.Render("Avg Staleness:"),
-		statsValueStyle.Render(fmt.Sprintf("%.2f days", m.avgStaleness)),
+		statsValueStyle.Render(fmt.Sprintf("%.2f days", avgStaleness)),
 		statsLabelStyle.Render("Scan Duration:"),
 		statsValueStyle.Render(m.scanDuration),
+		statsLabelStyle.Render("Selected:"),
+		statsValueStyle.Render(fmt.Sprintf("%d directories (%s)", len(m.selectedModules()), utils.FormatSize(m.selectedSize()))),
 	)
-	b.WriteString(statsStyle.Render(stats))
+	b.WriteString(statsStyle.MaxWidth(m.width).Render(stats))
 	b.WriteString("\n")
+	return b.String()
+}
 
-	// Table with border
-	tableBorder := lipgloss.NewStyle().
-		BorderStyle(lipgloss.RoundedBorder()).
-		BorderForeground(colorBorder).
-		Padding(1, 1)
+// footerView renders the progress, the notices and the keys below the table.
+func (m model) footerView() string {
+	var b strings.Builder
 
-	b.WriteString(tableBorder.Render(m.table.View()))
+	if progress := m.deleteProgressView(); progress != "" {
+		b.WriteString("\n")
+		b.WriteString(progress)
+	}
+
+	// Show why the last deletion failed
+	if m.deleteErr != nil {
+		b.WriteString("\n")
+		b.WriteString(deleteErrStyle.Width(m.width).Render("Delete failed: " + m.deleteErr.Error()))
+	}
+
+	if status := m.rescanStatus(); status != "" {
+		b.WriteString("\n")
+		b.WriteString(status)
+	}
+
+	if bar := m.filterBar(); bar != "" {
+		b.WriteString("\n")
+		b.WriteString(bar)
+	}
+
+	if m.notice != "" {
+		b.WriteString("\n")
+		b.WriteString(noticeStyle.Width(m.width).Render(m.notice))
+	}
 
 	// Footer with improved styling
 	b.WriteString("\n")
-	footerText := "q/Ctrl+C: quit • ↑/↓: navigate • space: delete"
+	footerText := m.help.ShortHelpView(m.keys.ShortHelp())
+	if m.tree != nil {
+		footerText = m.help.ShortHelpView(m.keys.treeHelp())
+	}
 	enhancedFooter := lipgloss.NewStyle().
 		Foreground(colorSecondary).
 		Align(lipgloss.Center).
@@ -397,7 +659,5 @@ func (m model) View() string {
 		Render(footerText)
 
 	b.WriteString(enhancedFooter)
-	b.WriteString("\n")
-
 	return b.String()
 }
@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/drxc00/sweepy/internal/config"
+	"github.com/drxc00/sweepy/types"
+)
+
+func TestDeletionUpdatesTotals(t *testing.T) {
+	t.Chdir(t.TempDir())
+	modules := []types.ScannedNodeModule{
+		{Path: "/r/a/node_modules", Size: 100, Staleness: 10},
+		{Path: "/r/b/node_modules", Size: 50, Staleness: 200},
+		{Path: "/r/c/node_modules", Size: 30, Staleness: 30},
+	}
+
+	tests := []struct {
+		name          string
+		deleted       []string
+		failed        bool
+		expectedSize  int64
+		expectedStale float64
+	}{
+		{name: "Nothing deleted", expectedSize: 180, expectedStale: 80},
+		{name: "One deleted", deleted: []string{"/r/b/node_modules"}, expectedSize: 130, expectedStale: 20},
+		{name: "Failed deletion kept", deleted: []string{"/r/b/node_modules"}, failed: true, expectedSize: 180, expectedStale: 80},
+		{name: "All deleted", deleted: []string{"/r/a/node_modules", "/r/b/node_modules", "/r/c/node_modules"}, expectedSize: 0, expectedStale: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var m tea.Model = initialModel(types.ScanContext{Path: "/r"}, config.Default(), false)
+			m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+			m, _ = m.Update(scanResultMsg{modules: slices.Clone(modules), stats: types.ScanInfo{TotalSize: 180, AvgStaleness: 80}})
+
+			for _, path := range test.deleted {
+				msg := deleteProgressMsg{Path: path, Done: true}
+				if test.failed {
+					msg.Err = errors.New("failed")
+				}
+				m, _ = m.Update(msg)
+			}
+
+			got := m.(model)
+			if got.totalSize != test.expectedSize {
+				t.Errorf("Expected total size %d, but got %d", test.expectedSize, got.totalSize)
+			}
+			if got.avgStaleness != test.expectedStale {
+				t.Errorf("Expected average staleness %.2f, but got %.2f", test.expectedStale, got.avgStaleness)
+			}
+		})
+	}
+}
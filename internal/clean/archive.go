@@ -0,0 +1,395 @@
+package clean
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/drxc00/sweepy/types"
+)
+
+// ArchiveExt is the extension of the archives written by ArchiveNodeModule.
+const ArchiveExt = ".tar.gz"
+
+var (
+	ErrArchiveExists   = errors.New("archive already exists")
+	ErrArchiveMismatch = errors.New("archive does not match the archived directory")
+	ErrRestoreExists   = errors.New("restore target already exists")
+	ErrNotSweepy       = errors.New("not an archive written by sweepy")
+)
+
+// ArchivePath returns where the archive of the node_modules directory p is written.
+// Without an archive root it sits next to the project, otherwise it is named after the full path of p.
+func ArchivePath(p, archiveRoot string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	if archiveRoot == "" {
+		return abs + ArchiveExt, nil
+	}
+
+	// "/home/me/app/node_modules" -> "home_me_app_node_modules.tar.gz"
+	name := strings.TrimPrefix(filepath.ToSlash(abs), "/")
+	name = strings.NewReplacer("/", "_", ":", "").Replace(name)
+	return filepath.Join(archiveRoot, name+ArchiveExt), nil
+}
+
+// ArchiveNodeModule writes the node_modules directory p to a gzipped tarball, reads the tarball back
+// to verify every file, and only then removes p. It returns the path of the archive.
+// The original path is stored in the gzip header so RestoreArchive knows where to unpack it.
+// Every attempt is recorded in the journal.
+func ArchiveNodeModule(p string, roots []string, archiveRoot string) (string, error) {
+	entry := newJournalEntry(p, types.JournalModeArchive, roots)
+	archive, err := archiveNodeModule(p, roots, archiveRoot, &entry)
+	recordAttempt(entry, err)
+	return archive, err
+}
+
+func archiveNodeModule(p string, roots []string, archiveRoot string, entry *types.JournalEntry) (string, error) {
+	info, err := CheckPath(p, roots)
+	if err != nil {
+		return "", err
+	}
+
+	archive, err := ArchivePath(p, archiveRoot)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Lstat(archive); err == nil {
+		return "", fmt.Errorf("%s: %w", archive, ErrArchiveExists)
+	}
+	if err := os.MkdirAll(filepath.Dir(archive), 0755); err != nil {
+		return "", err
+	}
+
+	// Written under a temporary name, a half written archive never looks like a good one
+	tmp := archive + ".part"
+	hashes, err := writeArchive(tmp, p)
+	if err == nil {
+		err = verifyArchive(tmp, hashes)
+	}
+	if err == nil {
+		err = os.Rename(tmp, archive)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	entry.Archive = archive
+	return archive, removeNodeModule(p, info, entry, nil)
+}
+
+// writeArchive writes the tree at p to archive and returns the sha256 of each file by entry name.
+func writeArchive(archive, p string) (map[string]string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(archive, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	gz.Comment = abs
+	tw := tar.NewWriter(gz)
+
+	hashes := make(map[string]string)
+	base := filepath.Dir(abs)
+	err = filepath.WalkDir(abs, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err // A file we cannot read would be lost, stop here
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if d.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		h := sha256.New()
+		if _, err := io.Copy(io.MultiWriter(tw, h), src); err != nil {
+			return err
+		}
+		hashes[header.Name] = hex.EncodeToString(h.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return hashes, f.Close()
+}
+
+// verifyArchive reads the archive back and compares every file with the hashes taken while writing.
+func verifyArchive(archive string, hashes map[string]string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+
+	seen := 0
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		h := sha256.New()
+		if _, err := io.Copy(h, tr); err != nil {
+			return err
+		}
+		if hashes[header.Name] != hex.EncodeToString(h.Sum(nil)) {
+			return fmt.Errorf("%s: %w", header.Name, ErrArchiveMismatch)
+		}
+		seen++
+	}
+
+	if seen != len(hashes) {
+		return fmt.Errorf("%d of %d files: %w", seen, len(hashes), ErrArchiveMismatch)
+	}
+	return nil
+}
+
+// ArchiveOrigin returns the path the archive was taken from.
+func ArchiveOrigin(archive string) (string, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return "", err
+	}
+	if gz.Comment == "" || !filepath.IsAbs(gz.Comment) {
+		return "", fmt.Errorf("%s: %w", archive, ErrNotSweepy)
+	}
+	return gz.Comment, nil
+}
+
+// RestoreArchive unpacks an archive written by ArchiveNodeModule back to its original path.
+// The path is read from the archive, so it is only trusted when it is a node_modules directory
+// next to the archive or under one of the roots.
+// It refuses to overwrite anything: the original path must not exist.
+// The tree is unpacked next to the target first and renamed into place once complete.
+func RestoreArchive(archive string, roots []string) (string, error) {
+	target, err := ArchiveOrigin(archive)
+	if err != nil {
+		return "", err
+	}
+	if err := checkRestoreTarget(target, archive, roots); err != nil {
+		return "", err
+	}
+	if _, err := os.Lstat(target); err == nil {
+		return "", fmt.Errorf("%s: %w", target, ErrRestoreExists)
+	}
+
+	parent := filepath.Dir(target)
+	tmp, err := os.MkdirTemp(parent, ".sweepy-restore-")
+	if err != nil {
+		return "", err
+	}
+	if err := extractArchive(archive, tmp); err != nil {
+		os.RemoveAll(tmp)
+		return "", err
+	}
+	if err := os.Rename(filepath.Join(tmp, filepath.Base(target)), target); err != nil {
+		os.RemoveAll(tmp)
+		return "", err
+	}
+	return target, os.RemoveAll(tmp)
+}
+
+// checkRestoreTarget makes sure the path read from an archive is one we may unpack to.
+func checkRestoreTarget(target, archive string, roots []string) error {
+	if !artifactNames[filepath.Base(target)] {
+		return fmt.Errorf("refusing to restore to %s: %w", target, ErrNotArtifact)
+	}
+
+	abs, err := filepath.Abs(archive)
+	if err != nil {
+		return err
+	}
+	// An archive written without an archive root sits in the project directory
+	next, err := filepath.EvalSymlinks(filepath.Dir(abs))
+	if err == nil && isDirectChild(target, next) {
+		return nil
+	}
+	if !isUnderRoots(target, roots) {
+		return fmt.Errorf("refusing to restore to %s: %w", target, ErrOutsideRoots)
+	}
+	return nil
+}
+
+// isDirectChild reports whether p, with its parent resolved, sits directly in dir.
+func isDirectChild(p, dir string) bool {
+	parent, err := filepath.EvalSymlinks(filepath.Dir(p))
+	return err == nil && parent == dir
+}
+
+// extractArchive unpacks archive into dir. Entries and symlinks escaping dir are refused.
+func extractArchive(archive, dir string) error {
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.FromSlash(header.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("%s: entry outside of the archive root", header.Name)
+		}
+		path := filepath.Join(dir, name)
+		mode := os.FileMode(header.Mode).Perm()
+
+		if header.Typeflag != tar.TypeDir && header.Typeflag != tar.TypeSymlink && header.Typeflag != tar.TypeReg {
+			continue // Devices and the like have no place in node_modules
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		// The links unpacked so far all stay inside dir, this catches anything they missed
+		parent, err := filepath.EvalSymlinks(filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		if !isUnderRoots(parent, []string{dir}) {
+			return fmt.Errorf("%s: entry outside of the archive root", header.Name)
+		}
+		path = filepath.Join(parent, filepath.Base(path))
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, mode|0700); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if !linkInside(dir, parent, header.Linkname) {
+				return fmt.Errorf("%s: link to %s outside of the archive root", header.Name, header.Linkname)
+			}
+			if err := os.Symlink(header.Linkname, path); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(path, tr, mode); err != nil {
+				return err
+			}
+			os.Chtimes(path, header.ModTime, header.ModTime) // Keep the staleness of the restored files
+		}
+	}
+}
+
+// linkInside reports whether a symlink in parent pointing to linkname stays inside dir.
+// Only a relative linkname is accepted, and ".." only at its start: after a symlink
+// it would climb from the target of the link rather than from where the name says.
+func linkInside(dir, parent, linkname string) bool {
+	if linkname == "" || filepath.IsAbs(linkname) || filepath.VolumeName(linkname) != "" {
+		return false
+	}
+	climbing := true
+	for _, part := range strings.Split(filepath.ToSlash(linkname), "/") {
+		if part != ".." {
+			climbing = false
+		} else if !climbing {
+			return false
+		}
+	}
+
+	rel, err := filepath.Rel(dir, filepath.Join(parent, linkname))
+	return err == nil && filepath.IsLocal(rel)
+}
+
+func writeFile(path string, r io.Reader, mode os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
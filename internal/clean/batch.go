@@ -0,0 +1,81 @@
+package clean
+
+import (
+	"sync"
+)
+
+// DefaultWorkers is how many directories BatchClean removes at the same time by default.
+// Removal is bound by the disk, more workers rarely help.
+const DefaultWorkers = 4
+
+// BatchProgress reports the removal of one directory of a batch.
+type BatchProgress struct {
+	Path  string
+	Files int64 // Removed so far
+	Bytes int64
+	Done  bool
+	Err   error // Set on the Done message when the removal failed
+}
+
+// Action removes one directory, reporting the files and bytes removed so far to onProgress.
+type Action func(p string, onProgress func(files, bytes int64)) error
+
+// RemoveAction deletes the directories, see CleanNodeModule.
+func RemoveAction(roots []string) Action {
+	return func(p string, onProgress func(files, bytes int64)) error {
+		return CleanNodeModuleWithProgress(p, roots, onProgress)
+	}
+}
+
+// TrashAction moves the directories to the trash, see TrashNodeModule.
+func TrashAction(roots []string) Action {
+	return func(p string, _ func(files, bytes int64)) error {
+		_, err := TrashNodeModule(p, roots)
+		return err
+	}
+}
+
+// ArchiveAction archives the directories before removing them, see ArchiveNodeModule.
+func ArchiveAction(roots []string, archiveRoot string) Action {
+	return func(p string, _ func(files, bytes int64)) error {
+		_, err := ArchiveNodeModule(p, roots, archiveRoot)
+		return err
+	}
+}
+
+// BatchClean removes the node_modules directories paths, at most workers of them at a time.
+// The progress of each directory is sent on progress and ends with a Done message.
+// It returns once every directory is handled, progress is left open so batches can share it.
+func BatchClean(paths []string, roots []string, workers int, progress chan<- BatchProgress) {
+	BatchRun(paths, RemoveAction(roots), workers, progress)
+}
+
+// BatchRun is BatchClean with any action applied to the directories.
+func BatchRun(paths []string, action Action, workers int, progress chan<- BatchProgress) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range jobs {
+				var files, bytes int64
+				err := action(p, func(f, b int64) {
+					files, bytes = f, b
+					progress <- BatchProgress{Path: p, Files: f, Bytes: b}
+				})
+				progress <- BatchProgress{Path: p, Files: files, Bytes: bytes, Done: true, Err: err}
+			}
+		}()
+	}
+
+	for _, p := range paths {
+		jobs <- p
+	}
+	close(jobs)
+	wg.Wait()
+}
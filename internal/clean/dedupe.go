@@ -0,0 +1,224 @@
+package clean
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/drxc00/sweepy/types"
+	"github.com/drxc00/sweepy/utils"
+)
+
+// ErrNotIdentical is returned when a file changed between the scan and linking it.
+var ErrNotIdentical = errors.New("file content differs from the kept copy")
+
+// fileEntry is a regular file found while looking for duplicates.
+type fileEntry struct {
+	path string
+	info os.FileInfo
+}
+
+// FindIdenticalFiles looks for byte-identical files in the given node_modules directories.
+// Files are first grouped by device, size and permissions, and only files sharing them are hashed.
+// Files that are already hard links of each other count once.
+func FindIdenticalFiles(dirs []string) ([]types.IdenticalFiles, error) {
+	type sizeKey struct {
+		device string
+		size   int64
+		mode   fs.FileMode // A link shares the mode of the kept file, executables stay executable
+	}
+	bySize := make(map[sizeKey][]fileEntry)
+	var order []sizeKey
+
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil // Unreadable directories are skipped
+			}
+			if !d.Type().IsRegular() {
+				return nil // Symlinks are never followed nor linked
+			}
+			info, err := d.Info()
+			if err != nil || info.Size() == 0 {
+				return nil
+			}
+
+			// Hard links cannot cross filesystems
+			device := filepath.VolumeName(p)
+			if id, ok := utils.DeviceID(info); ok {
+				device = fmt.Sprint(id)
+			}
+
+			k := sizeKey{device, info.Size(), info.Mode().Perm()}
+			if _, ok := bySize[k]; !ok {
+				order = append(order, k)
+			}
+			bySize[k] = append(bySize[k], fileEntry{path: p, info: info})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var sets []types.IdenticalFiles
+	for _, k := range order {
+		files := uniqueFiles(bySize[k])
+		if len(files) < 2 {
+			continue
+		}
+
+		byHash := make(map[string][]string)
+		var hashes []string
+		for _, f := range files {
+			hash, err := hashFile(f.path)
+			if err != nil {
+				utils.Log("Error hashing %s: %v\n", f.path, err)
+				continue
+			}
+			if _, ok := byHash[hash]; !ok {
+				hashes = append(hashes, hash)
+			}
+			byHash[hash] = append(byHash[hash], f.path)
+		}
+
+		for _, hash := range hashes {
+			if paths := byHash[hash]; len(paths) > 1 {
+				sets = append(sets, types.IdenticalFiles{Size: k.size, Hash: hash, Paths: paths})
+			}
+		}
+	}
+
+	slices.SortStableFunc(sets, func(a, b types.IdenticalFiles) int {
+		switch {
+		case a.Saved() > b.Saved():
+			return -1
+		case a.Saved() < b.Saved():
+			return 1
+		}
+		return 0
+	})
+	return sets, nil
+}
+
+// uniqueFiles drops the files that are hard links of a file already in the list.
+func uniqueFiles(files []fileEntry) []fileEntry {
+	var unique []fileEntry
+	for _, f := range files {
+		if !slices.ContainsFunc(unique, func(u fileEntry) bool { return os.SameFile(u.info, f.info) }) {
+			unique = append(unique, f)
+		}
+	}
+	return unique
+}
+
+func hashFile(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// LinkIdenticalFiles replaces every file of the set but the first with a hard link to the first.
+// Each file is compared byte by byte with the kept copy right before it is replaced,
+// and the link is checked afterwards. It returns the bytes saved and the first error,
+// the remaining files are still processed after an error.
+func LinkIdenticalFiles(set types.IdenticalFiles) (int64, error) {
+	keep := set.Paths[0]
+	var saved int64
+	var firstErr error
+	for _, p := range set.Paths[1:] {
+		if err := linkFile(keep, p); err != nil {
+			utils.Log("Error linking %s: %v\n", p, err)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", p, err)
+			}
+			continue
+		}
+		saved += set.Size
+	}
+	return saved, firstErr
+}
+
+// linkFile replaces p with a hard link to keep.
+// The link is created next to p and renamed over it, so p is never missing.
+func linkFile(keep, p string) error {
+	same, err := sameContent(keep, p)
+	if err != nil {
+		return err
+	}
+	if !same {
+		return ErrNotIdentical
+	}
+
+	tmp := p + ".sweepy-link"
+	if err := os.Link(keep, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	// Verify that p now is the kept file
+	keepInfo, err := os.Stat(keep)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		return err
+	}
+	if !os.SameFile(keepInfo, info) {
+		return fmt.Errorf("%s is not linked to %s after linking", p, keep)
+	}
+	return nil
+}
+
+// sameContent compares two files byte by byte.
+func sameContent(a, b string) (bool, error) {
+	fa, err := os.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer fa.Close()
+
+	fb, err := os.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer fb.Close()
+
+	bufA := make([]byte, 64*1024)
+	bufB := make([]byte, 64*1024)
+	for {
+		na, errA := io.ReadFull(fa, bufA)
+		nb, errB := io.ReadFull(fb, bufB)
+		if na != nb || !bytes.Equal(bufA[:na], bufB[:nb]) {
+			return false, nil
+		}
+		if errA == io.EOF || errA == io.ErrUnexpectedEOF {
+			return errB == io.EOF || errB == io.ErrUnexpectedEOF, nil
+		}
+		if errA != nil {
+			return false, errA
+		}
+		if errB != nil {
+			return false, errB
+		}
+	}
+}
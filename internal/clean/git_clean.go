@@ -0,0 +1,142 @@
+package clean
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/drxc00/sweepy/internal/git"
+	"github.com/drxc00/sweepy/types"
+)
+
+// Every deleted branch is written to this file inside the repository's .git directory,
+// in the same format as a reflog, so it can be restored with RestoreBranches (or by hand).
+const undoLogName = "sweepy-undo.log"
+
+// Deleted branches keep a ref under this prefix until they are restored.
+// Without it their commits are unreachable and the next git gc prunes them.
+const undoRefPrefix = "refs/sweepy-undo/"
+
+const zeroHash = "0000000000000000000000000000000000000000"
+
+var ErrBranchMoved = errors.New("branch has new commits since the scan")
+
+// DeleteBranch deletes a local branch found by scan.GitScan.
+// The branch is recorded in the undo log and kept under refs/sweepy-undo/ before its ref is removed.
+func DeleteBranch(b types.StaleBranch, roots []string) error {
+	if !isUnderRoots(b.Repo, roots) {
+		return &UnsafePathError{Path: b.Repo, Err: ErrOutsideRoots}
+	}
+
+	repo, err := git.Open(b.Repo)
+	if err != nil {
+		return err
+	}
+
+	// Never delete work that appeared after the user looked at the list
+	hash, err := repo.ResolveRef("refs/heads/" + b.Name)
+	if err != nil {
+		return err
+	}
+	if hash != b.Hash {
+		return fmt.Errorf("%w: %s", ErrBranchMoved, b.Name)
+	}
+
+	if err := appendUndoLog(repo, b.Hash, zeroHash, "delete", b.Name); err != nil {
+		return err
+	}
+	if err := repo.SetRef(undoRefPrefix+b.Name, b.Hash); err != nil {
+		return err
+	}
+	return repo.DeleteBranch(b.Name)
+}
+
+// RestoreBranches re-creates the branches sweepy deleted in the repository containing dir.
+// Branches that were restored already, or re-created since, are left alone.
+func RestoreBranches(dir string) ([]string, error) {
+	repo, err := git.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(repo.CommonDir, undoLogName))
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// Replay the log, the last entry of a branch decides if it still needs restoring
+	deleted := make(map[string]string)
+	var order []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields, message, ok := strings.Cut(scanner.Text(), "\t")
+		hashes := strings.Fields(fields)
+		if !ok || len(hashes) < 2 {
+			continue
+		}
+
+		action, name, _ := strings.Cut(message, ": ")
+		name = strings.TrimPrefix(name, "refs/heads/")
+		switch action {
+		case "delete":
+			if _, ok := deleted[name]; !ok {
+				order = append(order, name)
+			}
+			deleted[name] = hashes[0]
+		case "restore":
+			delete(deleted, name)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	restored := []string{}
+	for _, name := range order {
+		hash, ok := deleted[name]
+		if !ok {
+			continue
+		}
+		if err := repo.CreateBranch(name, hash); err != nil {
+			continue // Re-created by hand since
+		}
+		if err := appendUndoLog(repo, zeroHash, hash, "restore", name); err != nil {
+			return restored, err
+		}
+		// The branch keeps the commits now
+		if err := repo.DeleteRef(undoRefPrefix + name); err != nil {
+			return restored, err
+		}
+		restored = append(restored, name)
+	}
+	return restored, nil
+}
+
+func appendUndoLog(repo *git.Repo, oldHash string, newHash string, action string, branch string) error {
+	f, err := os.OpenFile(filepath.Join(repo.CommonDir, undoLogName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, offset := time.Now().Zone()
+	zone := fmt.Sprintf("%+03d%02d", offset/3600, abs(offset%3600)/60)
+	_, err = fmt.Fprintf(f, "%s %s sweepy <sweepy@localhost> %d %s\t%s: refs/heads/%s\n",
+		oldHash, newHash, time.Now().Unix(), zone, action, branch)
+	return err
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
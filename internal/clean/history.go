@@ -0,0 +1,86 @@
+package clean
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/drxc00/sweepy/types"
+)
+
+// JournalFilter selects journal entries, zero fields match everything.
+type JournalFilter struct {
+	Since  time.Time
+	Until  time.Time // Exclusive
+	Mode   string
+	Result string
+	User   string
+	Path   string // Substring of the path
+}
+
+// Match tells whether entry passes the filter.
+func (f JournalFilter) Match(entry types.JournalEntry) bool {
+	switch {
+	case !f.Since.IsZero() && entry.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !entry.Time.Before(f.Until):
+		return false
+	case f.Mode != "" && entry.Mode != f.Mode:
+		return false
+	case f.Result != "" && entry.Result != f.Result:
+		return false
+	case f.User != "" && entry.User != f.User:
+		return false
+	case f.Path != "" && !strings.Contains(entry.Path, f.Path):
+		return false
+	}
+	return true
+}
+
+// JournalTotal sums the journal entries of a period.
+type JournalTotal struct {
+	Period    string `json:"period"`
+	Attempts  int    `json:"attempts"`
+	Failed    int    `json:"failed"`
+	Reclaimed int64  `json:"reclaimed"` // Bytes of the successful removals
+}
+
+// TotalsBy groups entries by day, month, quarter or year, in the order of entries,
+// which is oldest first for the journal.
+// Any other period puts everything in a single "all" total.
+func TotalsBy(entries []types.JournalEntry, period string) []JournalTotal {
+	var totals []JournalTotal
+	index := make(map[string]int)
+	for _, entry := range entries {
+		key := periodOf(entry.Time, period)
+		i, ok := index[key]
+		if !ok {
+			i = len(totals)
+			index[key] = i
+			totals = append(totals, JournalTotal{Period: key})
+		}
+
+		totals[i].Attempts++
+		if entry.Result == types.JournalResultOK {
+			totals[i].Reclaimed += entry.Bytes
+		} else {
+			totals[i].Failed++
+		}
+	}
+	return totals
+}
+
+func periodOf(t time.Time, period string) string {
+	t = t.Local()
+	switch period {
+	case "day":
+		return t.Format("2006-01-02")
+	case "month":
+		return t.Format("2006-01")
+	case "quarter":
+		return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+	case "year":
+		return t.Format("2006")
+	}
+	return "all"
+}
@@ -0,0 +1,168 @@
+package clean
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/drxc00/sweepy/internal/project"
+	"github.com/drxc00/sweepy/types"
+	"github.com/drxc00/sweepy/utils"
+)
+
+// JournalEnv names the environment variable that overrides where the journal is kept.
+const JournalEnv = "SWEEPY_JOURNAL"
+
+// JournalPath returns the file every attempt to remove a node_modules is recorded in, one JSON object per line.
+// It is only ever appended to. Unlike the cache it is kept once per user, in sweepy/journal.jsonl under
+// the user config directory, so reinstall and history see the removals made from any directory.
+// JournalEnv overrides it.
+func JournalPath() string {
+	if p := os.Getenv(JournalEnv); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "sweepy.journal.jsonl" // No home directory, keep it next to the cache
+	}
+	return filepath.Join(dir, "sweepy", "journal.jsonl")
+}
+
+// Deletions run concurrently in the TUI, lines must not interleave
+var journalMu sync.Mutex
+
+// newJournalEntry describes the node_modules directory p before it is removed.
+func newJournalEntry(p string, mode string, roots []string) types.JournalEntry {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		abs = p
+	}
+
+	entry := types.JournalEntry{Time: time.Now(), Path: abs, User: currentUser(), Mode: mode}
+	dir := filepath.Dir(abs)
+	scope := project.NewScope(roots)
+	if meta, err := scope.ReadMeta(dir); err == nil {
+		entry.PackageManager = meta.PackageManager
+	}
+	if lockfile := scope.FindLockfile(dir); lockfile != "" {
+		entry.Lockfile = lockfile
+		entry.LockfileHash, _ = hashFile(lockfile)
+	}
+	return entry
+}
+
+// currentUser is recorded so shared machines can tell who cleaned what.
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
+
+// recordAttempt writes entry to the journal with the outcome of the removal.
+// removeNodeModule marks the entry ok as soon as the directory is gone,
+// a later error, e.g. while updating the cache, does not turn it into a failure.
+func recordAttempt(entry types.JournalEntry, err error) {
+	if entry.Result != types.JournalResultOK {
+		entry.Result = types.JournalResultFailed
+		if err != nil {
+			entry.Error = err.Error()
+		}
+	}
+
+	if err := appendJournal(entry); err != nil {
+		utils.Log("Error writing journal: %v\n", err)
+	}
+}
+
+// appendJournal adds entry at the end of the journal.
+func appendJournal(entry types.JournalEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	path := JournalPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadJournal returns the journal entries, oldest first.
+// A missing journal means nothing was removed yet and is not an error.
+func ReadJournal() ([]types.JournalEntry, error) {
+	f, err := os.Open(JournalPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []types.JournalEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry types.JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue // A line cut short by a crash, the rest is still good
+		}
+		// Older journals only recorded successful deletions
+		if entry.Mode == "" {
+			entry.Mode = types.JournalModeRemove
+		}
+		if entry.Result == "" {
+			entry.Result = types.JournalResultOK
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// LastRemoval returns the latest successful removal of the node_modules directory p.
+func LastRemoval(p string) (types.JournalEntry, bool, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return types.JournalEntry{}, false, err
+	}
+
+	entries, err := ReadJournal()
+	if err != nil {
+		return types.JournalEntry{}, false, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Path == abs && entries[i].Result == types.JournalResultOK {
+			return entries[i], true, nil
+		}
+	}
+	return types.JournalEntry{}, false, nil
+}
+
+// LockfileChanged tells whether the lockfile recorded in entry differs from the one on disk now.
+func LockfileChanged(entry types.JournalEntry) bool {
+	if entry.LockfileHash == "" {
+		return false
+	}
+	hash, err := hashFile(entry.Lockfile)
+	return err != nil || hash != entry.LockfileHash
+}
@@ -2,36 +2,79 @@ package clean
 
 import (
 	"errors"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/drxc00/sweepy/internal/cache"
+	"github.com/drxc00/sweepy/types"
 )
 
-func CleanNodeModule(p string) error {
-	// Check if node_modules exists
-	info, err := os.Stat(p)
-	if os.IsNotExist(err) {
-		return errors.New("node_modules does not exist or has been deleted")
-	}
+// How often removeTree reports its progress
+const progressInterval = 100 * time.Millisecond
+
+// Removals run concurrently, the cache file must be loaded and saved by one at a time
+var cacheMu sync.Mutex
+
+// CleanNodeModule deletes the node_modules directory p.
+// p must pass CheckPath against the scan roots, otherwise nothing is removed.
+// Every attempt is recorded in the journal.
+func CleanNodeModule(p string, roots []string) error {
+	return CleanNodeModuleWithProgress(p, roots, nil)
+}
+
+// CleanNodeModuleWithProgress is CleanNodeModule, calling onProgress with the number of files
+// and bytes removed so far while the directory is being removed. onProgress may be nil.
+func CleanNodeModuleWithProgress(p string, roots []string, onProgress func(files, bytes int64)) error {
+	entry := newJournalEntry(p, types.JournalModeRemove, roots)
+	err := cleanNodeModule(p, roots, &entry, onProgress)
+	recordAttempt(entry, err)
+	return err
+}
+
+func cleanNodeModule(p string, roots []string, entry *types.JournalEntry, onProgress func(files, bytes int64)) error {
+	info, err := CheckPath(p, roots)
 	if err != nil {
 		return err
 	}
+	return removeNodeModule(p, info, entry, onProgress)
+}
 
-	// Additional check to ensure it's a directory
-	if !info.IsDir() {
-		return errors.New("path is not a directory")
-	}
-
+// removeNodeModule removes p, checked by CheckPath, and drops it from the cache.
+// entry is marked ok once p is gone.
+func removeNodeModule(p string, info os.FileInfo, entry *types.JournalEntry, onProgress func(files, bytes int64)) error {
 	// Remove node_modules
 	// also remove it from the cache
-	if err := os.RemoveAll(p); err != nil {
-		return err
+	if info.Mode()&os.ModeSymlink != 0 {
+		// Delete the link, not the target
+		if err := os.Remove(p); err != nil {
+			return err
+		}
+	} else {
+		_, bytes, err := removeTree(p, onProgress)
+		entry.Bytes = bytes
+		if err != nil {
+			return err
+		}
 	}
+	entry.Result = types.JournalResultOK
+	return forgetCached(p)
+}
+
+// forgetCached drops the removed directory p from the scan cache.
+func forgetCached(p string) error {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
 
 	// Only load cache after successful removal
 	cache := cache.GetGlobalCache()
 	ok, c_err := cache.Load()
 
+	if errors.Is(c_err, os.ErrNotExist) {
+		return nil // Scanned with --no-cache, there is no cache file to update
+	}
 	if c_err != nil {
 		return c_err // Return the cache error, not the previous err
 	}
@@ -43,3 +86,42 @@ func CleanNodeModule(p string) error {
 	cache.Delete(p)
 	return cache.Save() // Return any error from Save directly
 }
+
+// removeTree removes the directory p file by file so the progress can be reported,
+// then removes the directories left with os.RemoveAll. It returns the files and bytes removed.
+func removeTree(p string, onProgress func(files, bytes int64)) (int64, int64, error) {
+	var files, bytes int64
+	last := time.Now()
+
+	filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil // os.RemoveAll below reports what could not be removed
+		}
+
+		var size int64
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size = info.Size()
+			}
+		}
+		if os.Remove(path) != nil {
+			return nil
+		}
+
+		files++
+		bytes += size
+		if onProgress != nil && time.Since(last) >= progressInterval {
+			onProgress(files, bytes)
+			last = time.Now()
+		}
+		return nil
+	})
+
+	if err := os.RemoveAll(p); err != nil {
+		return files, bytes, err
+	}
+	if onProgress != nil {
+		onProgress(files, bytes)
+	}
+	return files, bytes, nil
+}
@@ -0,0 +1,140 @@
+package clean
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/drxc00/sweepy/internal/git"
+	"github.com/drxc00/sweepy/utils"
+)
+
+// Artifact directory names that are safe to delete.
+// Anything else is refused, so a bad path can never wipe a project or a home directory.
+var artifactNames = map[string]bool{
+	"node_modules": true,
+}
+
+var (
+	ErrNotArtifact  = errors.New("not a known artifact directory")
+	ErrOutsideRoots = errors.New("path is outside the scan roots")
+	ErrMountPoint   = errors.New("path is a mount point")
+)
+
+// UnsafePathError is returned when a path fails one of the safety checks.
+// Use errors.Is with the Err* values above to find out which one.
+type UnsafePathError struct {
+	Path string
+	Err  error
+}
+
+func (e *UnsafePathError) Error() string {
+	return fmt.Sprintf("refusing to delete %s: %v", e.Path, e.Err)
+}
+
+func (e *UnsafePathError) Unwrap() error {
+	return e.Err
+}
+
+// CheckPath makes sure that p is an artifact directory we are allowed to delete.
+// It returns the Lstat info of p so the caller can tell if p is a symlink,
+// in which case only the link should be removed and never the target.
+func CheckPath(p string, roots []string) (os.FileInfo, error) {
+	info, err := os.Lstat(p)
+	if os.IsNotExist(err) {
+		return nil, errors.New("node_modules does not exist or has been deleted")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !artifactNames[filepath.Base(p)] {
+		return nil, &UnsafePathError{Path: p, Err: ErrNotArtifact}
+	}
+
+	if !isUnderRoots(p, roots) {
+		return nil, &UnsafePathError{Path: p, Err: ErrOutsideRoots}
+	}
+
+	// Symlinks are fine, the caller only unlinks them.
+	if info.Mode()&os.ModeSymlink != 0 {
+		return info, nil
+	}
+
+	// Additional check to ensure it's a directory
+	if !info.IsDir() {
+		return nil, errors.New("path is not a directory")
+	}
+
+	if isMountPoint(p, info) {
+		return nil, &UnsafePathError{Path: p, Err: ErrMountPoint}
+	}
+
+	return info, nil
+}
+
+// isUnderRoots reports whether p is inside (or is) one of the roots.
+// The parent directory is resolved so a symlink along the way cannot point us outside the root.
+func isUnderRoots(p string, roots []string) bool {
+	parent, err := filepath.EvalSymlinks(filepath.Dir(p))
+	if err != nil {
+		return false
+	}
+	resolved := filepath.Join(parent, filepath.Base(p))
+
+	for _, root := range roots {
+		r, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(r, resolved)
+		if err != nil {
+			continue
+		}
+		if rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator))) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMountPoint compares the device of p with the device of its parent.
+func isMountPoint(p string, info os.FileInfo) bool {
+	parentInfo, err := os.Stat(filepath.Dir(p))
+	if err != nil {
+		return false
+	}
+
+	dev, ok := utils.DeviceID(info)
+	parentDev, parentOk := utils.DeviceID(parentInfo)
+	if !ok || !parentOk {
+		return false
+	}
+	return dev != parentDev
+}
+
+// UnsavedWork re-reads the git status of the project in dir right before its deps are deleted.
+// It returns what would be at risk, or an empty string when there is nothing to lose.
+// A repository that cannot be read counts as unsaved, it may hold anything.
+func UnsavedWork(dir string) string {
+	status, err := git.Status(dir)
+	if errors.Is(err, git.ErrNotRepo) {
+		return ""
+	}
+	if err != nil {
+		utils.Log("Error reading git status: %v\n", err)
+		return "git status unknown: " + err.Error()
+	}
+
+	switch {
+	case status.Dirty && status.Unpushed:
+		return "uncommitted changes and unpushed commits"
+	case status.Dirty:
+		return "uncommitted changes"
+	case status.Unpushed:
+		return "unpushed commits"
+	}
+	return ""
+}
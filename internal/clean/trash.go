@@ -0,0 +1,47 @@
+package clean
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/drxc00/sweepy/internal/scan"
+	"github.com/drxc00/sweepy/types"
+)
+
+var (
+	ErrTrashUnsupported = errors.New("moving to the trash is not supported on this platform")
+	ErrTrashOtherDevice = errors.New("the trash is on another filesystem")
+)
+
+// TrashNodeModule moves the node_modules directory p to the trash of the current user,
+// where it can be restored from until the trash is emptied. It returns where p was moved.
+// Every attempt is recorded in the journal.
+func TrashNodeModule(p string, roots []string) (string, error) {
+	entry := newJournalEntry(p, types.JournalModeTrash, roots)
+	dest, err := trashNodeModule(p, roots, &entry)
+	recordAttempt(entry, err)
+	return dest, err
+}
+
+func trashNodeModule(p string, roots []string, entry *types.JournalEntry) (string, error) {
+	info, err := CheckPath(p, roots)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		entry.Bytes, _ = scan.DirSizeFastWalk(p)
+	}
+
+	dest, err := moveToTrash(entry.Path)
+	if err != nil {
+		return "", err
+	}
+	entry.Trash = dest
+	entry.Result = types.JournalResultOK
+	return dest, forgetCached(p)
+}
+
+// trashName names node_modules after its project in the trash, "app-node_modules".
+func trashName(p string) string {
+	return filepath.Base(filepath.Dir(p)) + "-" + filepath.Base(p)
+}
@@ -0,0 +1,92 @@
+//go:build !windows
+
+package clean
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// moveToTrash moves p to ~/.Trash on macOS, and follows the freedesktop.org trash
+// specification elsewhere, so file managers can restore it.
+func moveToTrash(p string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	if runtime.GOOS == "darwin" {
+		dir := filepath.Join(home, ".Trash")
+		for i := 1; ; i++ {
+			dest := filepath.Join(dir, numbered(trashName(p), i))
+			if _, err := os.Lstat(dest); err == nil {
+				continue
+			}
+			return dest, renameToTrash(p, dest)
+		}
+	}
+
+	trash := filepath.Join(home, ".local", "share", "Trash")
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		trash = filepath.Join(dataHome, "Trash")
+	}
+	if err := os.MkdirAll(filepath.Join(trash, "files"), 0700); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Join(trash, "info"), 0700); err != nil {
+		return "", err
+	}
+
+	info := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		(&url.URL{Path: p}).EscapedPath(), time.Now().Format("2006-01-02T15:04:05"))
+
+	for i := 1; ; i++ {
+		name := numbered(trashName(p), i)
+
+		// The info file is created first and exclusively, it reserves the name
+		infoPath := filepath.Join(trash, "info", name+".trashinfo")
+		f, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = f.WriteString(info)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+
+		dest := filepath.Join(trash, "files", name)
+		if err == nil {
+			err = renameToTrash(p, dest)
+		}
+		if err != nil {
+			os.Remove(infoPath)
+			return "", err
+		}
+		return dest, nil
+	}
+}
+
+func renameToTrash(p, dest string) error {
+	err := os.Rename(p, dest)
+	if errors.Is(err, syscall.EXDEV) {
+		return ErrTrashOtherDevice // Copying would take as long as deleting, and fill the home disk
+	}
+	return err
+}
+
+// numbered returns name for the first try, then "name.2", "name.3"...
+func numbered(name string, i int) string {
+	if i == 1 {
+		return name
+	}
+	return fmt.Sprintf("%s.%d", name, i)
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package clean
+
+// moveToTrash needs the shell API to reach the recycle bin, which we do not call.
+func moveToTrash(p string) (string, error) {
+	return "", ErrTrashUnsupported
+}
@@ -0,0 +1,255 @@
+/*
+	This package holds the user settings read from sweepy.config.json.
+	Like the cache and the journal, the file sits in the current directory.
+	Every setting is optional, a missing file means the defaults.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/drxc00/sweepy/utils"
+)
+
+// ConfigFile is where the settings are read from.
+const ConfigFile = "sweepy.config.json"
+
+// Columns the results table can be sorted by
+const (
+	SortSize      = "size"
+	SortStaleness = "staleness"
+	SortModified  = "modified"
+	SortProject   = "project"
+	SortPath      = "path"
+)
+
+// SortColumns lists the sort columns in the order the s key cycles through them.
+var SortColumns = []string{SortSize, SortStaleness, SortModified, SortProject, SortPath}
+
+// KeyActions lists the TUI actions whose keys can be remapped in the config.
+var KeyActions = []string{
+	"quit", "help", "up", "down", "open", "expand", "collapse", "back",
+	"select", "selectAll", "selectNone", "invert", "olderThan", "filter",
+	"sort", "reverse", "delete", "trash", "archive", "details", "tree",
+	"rescan", "rescanNoCache",
+}
+
+// DefaultKeys are the keys of each TUI action that the config does not remap.
+var DefaultKeys = map[string][]string{
+	"quit": {"q"}, "help": {"?"}, "up": {"up"}, "down": {"down"},
+	"open": {"enter"}, "expand": {"right"}, "collapse": {"left"}, "back": {"esc", "backspace"},
+	"select": {"space"}, "selectAll": {"a"}, "selectNone": {"n"}, "invert": {"i"},
+	"olderThan": {"o"}, "filter": {"/"}, "sort": {"s"}, "reverse": {"S"},
+	"delete": {"d"}, "trash": {"t"}, "archive": {"z"}, "details": {"p"}, "tree": {"v"},
+	"rescan": {"r"}, "rescanNoCache": {"R"},
+}
+
+// Color themes of the TUI
+const (
+	ThemeDark         = "dark"
+	ThemeLight        = "light"
+	ThemeHighContrast = "high-contrast"
+	ThemeMonochrome   = "monochrome"
+)
+
+var Themes = []string{ThemeDark, ThemeLight, ThemeHighContrast, ThemeMonochrome}
+
+// ColorNames lists the theme colors that can be replaced in the config.
+var ColorNames = []string{
+	"primary", "secondary", "border", "selected", "error", "warning", "header",
+	"muted", "accent", "success", "deleting", "fresh", "stale", "old", "ancient",
+}
+
+// A color is an ANSI 256 code or a hex RGB value
+var colorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{6}|#[0-9a-fA-F]{3}|[0-9]{1,3})$`)
+
+type Sort struct {
+	Column     string `json:"column"`
+	Descending bool   `json:"descending"`
+}
+
+// StalenessThresholds are the days above which the TUI colors a staleness as stale, old and ancient.
+type StalenessThresholds struct {
+	Stale   int64 `json:"stale"`
+	Old     int64 `json:"old"`
+	Ancient int64 `json:"ancient"`
+}
+
+type Config struct {
+	// Selections bigger than this need "yes" typed out before they are deleted, e.g. "10GB". "0" disables it.
+	ConfirmTypeYesAbove string `json:"confirmTypeYesAbove,omitempty"`
+
+	// Order of the results table, updated when it is changed in the TUI
+	Sort Sort `json:"sort"`
+
+	// Keys per TUI action, replacing its default keys, e.g. {"up": ["k", "up"]}
+	Keys map[string][]string `json:"keys,omitempty"`
+
+	// Color theme of the TUI, one of Themes. NO_COLOR in the environment forces monochrome.
+	Theme string `json:"theme,omitempty"`
+
+	// Colors replacing the ones of the theme by name, e.g. {"primary": "#ff79c6"}
+	Colors map[string]string `json:"colors,omitempty"`
+
+	StalenessThresholds StalenessThresholds `json:"stalenessThresholds"`
+}
+
+// Default returns the settings used when sweepy.config.json does not set them.
+func Default() Config {
+	return Config{
+		ConfirmTypeYesAbove: "10GB",
+		Sort:                Sort{Column: SortSize, Descending: true},
+		Theme:               ThemeDark,
+		StalenessThresholds: StalenessThresholds{Stale: 90, Old: 180, Ancient: 365},
+	}
+}
+
+// Load reads sweepy.config.json over the defaults and checks the values.
+func Load() (Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(ConfigFile)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", ConfigFile, err)
+	}
+
+	if cfg.ConfirmTypeYesAbove == "" {
+		cfg.ConfirmTypeYesAbove = Default().ConfirmTypeYesAbove
+	}
+	if _, err := utils.ParseSize(cfg.ConfirmTypeYesAbove); err != nil {
+		return cfg, fmt.Errorf("%s: confirmTypeYesAbove: %w", ConfigFile, err)
+	}
+	if cfg.Sort.Column == "" {
+		cfg.Sort = Default().Sort
+	}
+	if !slices.Contains(SortColumns, cfg.Sort.Column) {
+		return cfg, fmt.Errorf("%s: sort: unknown column %q, use one of %s", ConfigFile, cfg.Sort.Column, strings.Join(SortColumns, ", "))
+	}
+	for action, keys := range cfg.Keys {
+		if !slices.Contains(KeyActions, action) {
+			return cfg, fmt.Errorf("%s: keys: unknown action %q, use one of %s", ConfigFile, action, strings.Join(KeyActions, ", "))
+		}
+		if len(keys) == 0 {
+			return cfg, fmt.Errorf("%s: keys: no keys for %q", ConfigFile, action)
+		}
+	}
+	// A key does one thing, the actions that were not remapped keep their default keys
+	bound := make(map[string]string)
+	for _, action := range KeyActions {
+		keys, ok := cfg.Keys[action]
+		if !ok {
+			keys = DefaultKeys[action]
+		}
+		for _, k := range keys {
+			if k == " " {
+				k = "space"
+			}
+			if other, ok := bound[k]; ok && other != action {
+				return cfg, fmt.Errorf("%s: keys: %q is bound to both %q and %q", ConfigFile, k, other, action)
+			}
+			bound[k] = action
+		}
+	}
+	if cfg.Theme == "" {
+		cfg.Theme = Default().Theme
+	}
+	if !slices.Contains(Themes, cfg.Theme) {
+		return cfg, fmt.Errorf("%s: theme: unknown theme %q, use one of %s", ConfigFile, cfg.Theme, strings.Join(Themes, ", "))
+	}
+	for name, color := range cfg.Colors {
+		if !slices.Contains(ColorNames, name) {
+			return cfg, fmt.Errorf("%s: colors: unknown color %q, use one of %s", ConfigFile, name, strings.Join(ColorNames, ", "))
+		}
+		if code, err := strconv.Atoi(color); !colorPattern.MatchString(color) || (err == nil && code > 255) {
+			return cfg, fmt.Errorf("%s: colors: invalid %s color %q, use an ANSI code from 0 to 255 or a hex value like #ff79c6", ConfigFile, name, color)
+		}
+	}
+	t := cfg.StalenessThresholds
+	if t.Stale <= 0 || t.Old <= t.Stale || t.Ancient <= t.Old {
+		return cfg, fmt.Errorf("%s: stalenessThresholds: need 0 < stale < old < ancient, got %d, %d and %d", ConfigFile, t.Stale, t.Old, t.Ancient)
+	}
+	return cfg, nil
+}
+
+// SaveSort stores sort as the default order in sweepy.config.json.
+// Only the sort key is written, the other settings of the file are kept as they are
+// and the defaults are not frozen into it.
+func SaveSort(sort Sort) error {
+	settings := make(map[string]json.RawMessage)
+	data, err := os.ReadFile(ConfigFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return fmt.Errorf("%s: %w", ConfigFile, err)
+		}
+	}
+
+	value, err := json.Marshal(sort)
+	if err != nil {
+		return err
+	}
+	settings["sort"] = value
+
+	b, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(ConfigFile, append(b, '\n'))
+}
+
+// writeFileAtomic writes data to a temporary file next to name and renames it into place,
+// so the file is never left half written.
+func writeFileAtomic(name string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(name); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, mode)
+	}
+	if err == nil {
+		err = os.Rename(tmp, name)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// TypeYesAbove returns the size above which "yes" must be typed, 0 when it is never required.
+func (c Config) TypeYesAbove() int64 {
+	size, _ := utils.ParseSize(c.ConfirmTypeYesAbove) // Checked by Load
+	return size
+}
+
+// NeedsTypedYes tells whether deleting size bytes at once needs "yes" typed out.
+func (c Config) NeedsTypedYes(size int64) bool {
+	threshold := c.TypeYesAbove()
+	return threshold > 0 && size > threshold
+}
@@ -0,0 +1,210 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrCurrentBranch = errors.New("branch is checked out")
+
+// Branches returns the local branches, keyed by name (without "refs/heads/"), and their hashes.
+func (r *Repo) Branches() (map[string]string, error) {
+	branches := make(map[string]string)
+
+	packed, err := r.PackedRefs()
+	if err != nil {
+		return nil, err
+	}
+	for ref, hash := range packed {
+		if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			branches[name] = hash
+		}
+	}
+
+	// Loose refs win over packed ones
+	headsDir := filepath.Join(r.CommonDir, "refs", "heads")
+	err = filepath.WalkDir(headsDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(headsDir, p)
+		if err != nil {
+			return err
+		}
+		branches[filepath.ToSlash(rel)] = strings.TrimSpace(string(b))
+		return nil
+	})
+	return branches, err
+}
+
+// DefaultBranch guesses the branch other branches get merged into.
+// The remote HEAD (refs/remotes/origin/HEAD) is preferred, then main and master.
+// It returns the branch name and its hash, or ErrRefNotFound when there is no good guess.
+func (r *Repo) DefaultBranch() (string, string, error) {
+	branches, err := r.Branches()
+	if err != nil {
+		return "", "", err
+	}
+
+	if content, err := r.readLooseRef("refs/remotes/origin/HEAD"); err == nil {
+		if target, ok := strings.CutPrefix(content, "ref: refs/remotes/origin/"); ok {
+			if hash, ok := branches[target]; ok {
+				return target, hash, nil
+			}
+			if hash, err := r.ResolveRef("refs/remotes/origin/" + target); err == nil {
+				return target, hash, nil
+			}
+		}
+	}
+
+	for _, name := range []string{"main", "master"} {
+		if hash, ok := branches[name]; ok {
+			return name, hash, nil
+		}
+	}
+	return "", "", ErrRefNotFound
+}
+
+// CheckedOutBranches returns the refs (e.g. "refs/heads/main") checked out in the main
+// worktree and in every linked worktree of the repository.
+func (r *Repo) CheckedOutBranches() (map[string]bool, error) {
+	heads := []string{filepath.Join(r.CommonDir, "HEAD")}
+	linked, err := filepath.Glob(filepath.Join(r.CommonDir, "worktrees", "*", "HEAD"))
+	if err != nil {
+		return nil, err
+	}
+	heads = append(heads, linked...)
+
+	checkedOut := make(map[string]bool)
+	for _, head := range heads {
+		b, err := os.ReadFile(head)
+		if errors.Is(err, os.ErrNotExist) {
+			continue // A worktree being removed
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ref, ok := strings.CutPrefix(strings.TrimSpace(string(b)), "ref: "); ok {
+			checkedOut[ref] = true
+		}
+	}
+	return checkedOut, nil
+}
+
+// DeleteBranch removes a local branch, both its loose ref and its packed-refs entry.
+// A branch checked out in any worktree is never deleted.
+func (r *Repo) DeleteBranch(name string) error {
+	ref := "refs/heads/" + name
+
+	checkedOut, err := r.CheckedOutBranches()
+	if err != nil {
+		return err
+	}
+	if checkedOut[ref] {
+		return fmt.Errorf("%w: %s", ErrCurrentBranch, name)
+	}
+
+	if err := r.DeleteRef(ref); err != nil {
+		return err
+	}
+	// Like git, the reflog goes with the branch
+	if err := os.Remove(filepath.Join(r.CommonDir, "logs", filepath.FromSlash(ref))); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// DeleteRef removes ref, both its loose file and its packed-refs entry.
+// It is not an error if there is neither.
+func (r *Repo) DeleteRef(ref string) error {
+	loose := filepath.Join(r.CommonDir, filepath.FromSlash(ref))
+	if err := os.Remove(loose); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return r.removePackedRef(ref)
+}
+
+// removePackedRef rewrites packed-refs without ref, using the same lock file git uses.
+func (r *Repo) removePackedRef(ref string) error {
+	packedPath := filepath.Join(r.CommonDir, "packed-refs")
+	b, err := os.ReadFile(packedPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var kept []string
+	removed := false
+	skipPeeled := false
+	for _, line := range strings.SplitAfter(string(b), "\n") {
+		if line == "" {
+			continue
+		}
+		// A peeled line (^hash) belongs to the ref above it
+		if strings.HasPrefix(line, "^") && skipPeeled {
+			continue
+		}
+		skipPeeled = false
+
+		if _, name, ok := strings.Cut(strings.TrimSpace(line), " "); ok && name == ref {
+			removed = true
+			skipPeeled = true
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if !removed {
+		return nil
+	}
+
+	lockPath := packedPath + ".lock"
+	lock, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("packed-refs is locked: %w", err)
+	}
+	if _, err := lock.WriteString(strings.Join(kept, "")); err != nil {
+		lock.Close()
+		os.Remove(lockPath)
+		return err
+	}
+	if err := lock.Close(); err != nil {
+		os.Remove(lockPath)
+		return err
+	}
+	return os.Rename(lockPath, packedPath)
+}
+
+// CreateBranch points a new branch at hash. It fails if the branch already exists.
+func (r *Repo) CreateBranch(name string, hash string) error {
+	ref := "refs/heads/" + name
+	if _, err := r.ResolveRef(ref); err == nil {
+		return fmt.Errorf("branch %s already exists", name)
+	}
+	return r.SetRef(ref, hash)
+}
+
+// SetRef writes ref as a loose ref pointing at hash, replacing what it pointed to.
+func (r *Repo) SetRef(ref string, hash string) error {
+	p := filepath.Join(r.CommonDir, filepath.FromSlash(ref))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, []byte(hash+"\n"), 0644)
+}
@@ -0,0 +1,89 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrNoCommits = errors.New("repository has no commits")
+
+type Commit struct {
+	Hash          string
+	Tree          string
+	Parents       []string
+	CommitterTime time.Time
+}
+
+// ReadCommit reads and parses the commit object with the given hash.
+func (r *Repo) ReadCommit(hash string) (Commit, error) {
+	typ, data, err := r.ReadObject(hash)
+	if err != nil {
+		return Commit{}, err
+	}
+	if typ != "commit" {
+		return Commit{}, fmt.Errorf("object %s is a %s, not a commit", hash, typ)
+	}
+
+	commit := Commit{Hash: hash}
+
+	// The header ends at the first empty line, the rest is the message
+	header, _, _ := bytes.Cut(data, []byte("\n\n"))
+	for _, line := range strings.Split(string(header), "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "tree":
+			commit.Tree = value
+		case "parent":
+			commit.Parents = append(commit.Parents, value)
+		case "committer":
+			commit.CommitterTime = parseSignatureTime(value)
+		}
+	}
+	return commit, nil
+}
+
+// parseSignatureTime extracts the time from "Name <email> 1700000000 +0100".
+func parseSignatureTime(signature string) time.Time {
+	fields := strings.Fields(signature)
+	if len(fields) < 2 {
+		return time.Time{}
+	}
+
+	unix, err := strconv.ParseInt(fields[len(fields)-2], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(unix, 0)
+}
+
+// LastCommit returns the time of the commit HEAD points to and a short name for HEAD:
+// the branch name, or the abbreviated hash when HEAD is detached.
+func LastCommit(dir string) (time.Time, string, error) {
+	repo, err := Open(dir)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	ref, hash, err := repo.Head()
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	if hash == "" {
+		return time.Time{}, "", ErrNoCommits
+	}
+
+	commit, err := repo.ReadCommit(hash)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	name := strings.TrimPrefix(ref, "refs/heads/")
+	if name == "" && len(hash) >= 7 {
+		name = hash[:7]
+	}
+	return commit.CommitterTime, name, nil
+}
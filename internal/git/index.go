@@ -0,0 +1,136 @@
+package git
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Entry modes we need to tell apart
+const (
+	modeSymlink = 0o120000
+	modeGitlink = 0o160000 // Submodule
+)
+
+type IndexEntry struct {
+	Path         string
+	Hash         string
+	Mode         uint32
+	Size         uint32 // Truncated to 32 bits, like git does
+	MtimeSeconds uint32
+	MtimeNanos   uint32
+	Stage        int  // Non zero for merge conflicts
+	SkipWorktree bool // Sparse checkout, the file is not expected on disk
+}
+
+// ReadIndex parses the index (staging area) of the repository.
+// Versions 2, 3 and 4 are supported, extensions are ignored.
+func (r *Repo) ReadIndex() ([]IndexEntry, error) {
+	b, err := os.ReadFile(filepath.Join(r.GitDir, "index"))
+	if errors.Is(err, os.ErrNotExist) {
+		return []IndexEntry{}, nil // Nothing staged yet
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	errCorrupt := errors.New("corrupt git index")
+	if len(b) < 12 || !bytes.Equal(b[:4], []byte("DIRC")) {
+		return nil, errCorrupt
+	}
+	version := binary.BigEndian.Uint32(b[4:8])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("unsupported git index version %d", version)
+	}
+	count := int(binary.BigEndian.Uint32(b[8:12]))
+
+	entries := make([]IndexEntry, 0, count)
+	pos := 12
+	prevPath := ""
+	for range count {
+		// ctime, mtime, dev, ino, mode, uid, gid, size, hash and flags
+		if len(b) < pos+62 {
+			return nil, errCorrupt
+		}
+		start := pos
+		entry := IndexEntry{
+			MtimeSeconds: binary.BigEndian.Uint32(b[pos+8:]),
+			MtimeNanos:   binary.BigEndian.Uint32(b[pos+12:]),
+			Mode:         binary.BigEndian.Uint32(b[pos+24:]),
+			Size:         binary.BigEndian.Uint32(b[pos+36:]),
+			Hash:         hex.EncodeToString(b[pos+40 : pos+60]),
+		}
+		flags := binary.BigEndian.Uint16(b[pos+60:])
+		entry.Stage = int(flags>>12) & 3
+		pos += 62
+
+		if flags&0x4000 != 0 && version >= 3 {
+			if len(b) < pos+2 {
+				return nil, errCorrupt
+			}
+			extended := binary.BigEndian.Uint16(b[pos:])
+			entry.SkipWorktree = extended&0x4000 != 0
+			pos += 2
+		}
+
+		if version == 4 {
+			// The path is stored as "drop N bytes of the previous path" plus a suffix
+			drop, n := readVarint(b[pos:])
+			if n == 0 || drop > len(prevPath) {
+				return nil, errCorrupt
+			}
+			pos += n
+			end := bytes.IndexByte(b[pos:], 0)
+			if end < 0 {
+				return nil, errCorrupt
+			}
+			entry.Path = prevPath[:len(prevPath)-drop] + string(b[pos:pos+end])
+			pos += end + 1
+		} else {
+			end := bytes.IndexByte(b[pos:], 0)
+			if end < 0 {
+				return nil, errCorrupt
+			}
+			entry.Path = string(b[pos : pos+end])
+			// Entries are padded with NULs to a multiple of 8 bytes
+			pos = start + ((pos+end-start)/8+1)*8
+		}
+
+		prevPath = entry.Path
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// readVarint decodes the variable length integers of index v4 (the same encoding as offset deltas).
+// It returns the value and the number of bytes read, 0 when b is too short.
+func readVarint(b []byte) (int, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+	c := b[0]
+	val := int(c & 0x7f)
+	n := 1
+	for c&0x80 != 0 {
+		if n >= len(b) {
+			return 0, 0
+		}
+		c = b[n]
+		n++
+		val = ((val + 1) << 7) | int(c&0x7f)
+	}
+	return val, n
+}
+
+// hashBlob computes the object hash git would give the content.
+func hashBlob(content []byte) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,358 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var ErrObjectNotFound = errors.New("object not found")
+
+// Object types as stored in pack files
+const (
+	objCommit   = 1
+	objTree     = 2
+	objBlob     = 3
+	objTag      = 4
+	objOfsDelta = 6
+	objRefDelta = 7
+)
+
+var objTypeNames = map[int]string{
+	objCommit: "commit",
+	objTree:   "tree",
+	objBlob:   "blob",
+	objTag:    "tag",
+}
+
+// Deltas can be chained, this limits how deep we follow them.
+const maxDeltaDepth = 64
+
+// ReadObject returns the type ("commit", "tree", "blob" or "tag") and content of an object.
+// Loose objects are checked first, then every pack file.
+func (r *Repo) ReadObject(hash string) (string, []byte, error) {
+	return r.readObject(hash, 0)
+}
+
+// readObject reads an object depth deltas down a chain, ref deltas resolve their base through it.
+func (r *Repo) readObject(hash string, depth int) (string, []byte, error) {
+	typ, data, err := r.readLooseObject(hash)
+	if !errors.Is(err, os.ErrNotExist) {
+		return typ, data, err
+	}
+
+	if err := r.loadPacks(); err != nil {
+		return "", nil, err
+	}
+
+	for _, p := range r.packs {
+		offset, ok := p.find(hash)
+		if !ok {
+			continue
+		}
+		return r.readPackedObject(p, offset, depth)
+	}
+
+	return "", nil, fmt.Errorf("%w: %s", ErrObjectNotFound, hash)
+}
+
+func (r *Repo) readLooseObject(hash string) (string, []byte, error) {
+	if len(hash) < 3 {
+		return "", nil, os.ErrNotExist
+	}
+
+	f, err := os.Open(filepath.Join(r.CommonDir, "objects", hash[:2], hash[2:]))
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	zr, err := zlib.NewReader(f)
+	if err != nil {
+		return "", nil, err
+	}
+	defer zr.Close()
+
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Loose objects start with "<type> <size>\x00"
+	header, data, ok := bytes.Cut(raw, []byte{0})
+	if !ok {
+		return "", nil, fmt.Errorf("invalid object header: %s", hash)
+	}
+	typ, _, _ := strings.Cut(string(header), " ")
+	return typ, data, nil
+}
+
+// --- Pack files ---
+
+type pack struct {
+	path    string // Path to the .pack file
+	hashes  []byte // Sorted object hashes, 20 bytes each
+	offsets []int64
+}
+
+func (p *pack) count() int {
+	return len(p.offsets)
+}
+
+// find looks up the offset of an object in the pack with a binary search.
+func (p *pack) find(hash string) (int64, bool) {
+	want, err := hex.DecodeString(hash)
+	if err != nil || len(want) != 20 {
+		return 0, false
+	}
+
+	i := sort.Search(p.count(), func(i int) bool {
+		return bytes.Compare(p.hashes[i*20:i*20+20], want) >= 0
+	})
+	if i < p.count() && bytes.Equal(p.hashes[i*20:i*20+20], want) {
+		return p.offsets[i], true
+	}
+	return 0, false
+}
+
+func (r *Repo) loadPacks() error {
+	if r.packs != nil {
+		return nil
+	}
+	r.packs = []*pack{}
+
+	idxFiles, err := filepath.Glob(filepath.Join(r.CommonDir, "objects", "pack", "*.idx"))
+	if err != nil {
+		return err
+	}
+
+	for _, idx := range idxFiles {
+		p, err := readPackIndex(idx)
+		if err != nil {
+			return err
+		}
+		r.packs = append(r.packs, p)
+	}
+	return nil
+}
+
+// readPackIndex parses a version 2 .idx file.
+func readPackIndex(idxPath string) (*pack, error) {
+	b, err := os.ReadFile(idxPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) < 8+256*4 || !bytes.Equal(b[:4], []byte{0xff, 't', 'O', 'c'}) || binary.BigEndian.Uint32(b[4:8]) != 2 {
+		return nil, fmt.Errorf("unsupported pack index: %s", idxPath)
+	}
+
+	fanout := b[8 : 8+256*4]
+	n := int(binary.BigEndian.Uint32(fanout[255*4:]))
+
+	hashStart := 8 + 256*4
+	crcStart := hashStart + n*20
+	offsetStart := crcStart + n*4
+	largeStart := offsetStart + n*4
+	if len(b) < largeStart {
+		return nil, fmt.Errorf("truncated pack index: %s", idxPath)
+	}
+
+	offsets := make([]int64, n)
+	for i := range n {
+		off := binary.BigEndian.Uint32(b[offsetStart+i*4:])
+		if off&0x80000000 != 0 {
+			// The offset does not fit in 31 bits, it lives in the 64 bit table
+			j := largeStart + int(off&0x7fffffff)*8
+			if len(b) < j+8 {
+				return nil, fmt.Errorf("truncated pack index: %s", idxPath)
+			}
+			offsets[i] = int64(binary.BigEndian.Uint64(b[j:]))
+		} else {
+			offsets[i] = int64(off)
+		}
+	}
+
+	return &pack{
+		path:    strings.TrimSuffix(idxPath, ".idx") + ".pack",
+		hashes:  b[hashStart:crcStart],
+		offsets: offsets,
+	}, nil
+}
+
+func (r *Repo) readPackedObject(p *pack, offset int64, depth int) (string, []byte, error) {
+	if depth > maxDeltaDepth {
+		return "", nil, errors.New("delta chain too deep")
+	}
+
+	f, err := os.Open(p.path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(io.NewSectionReader(f, offset, 1<<62))
+
+	// Object header: 3 bit type and a variable length size
+	c, err := br.ReadByte()
+	if err != nil {
+		return "", nil, err
+	}
+	typ := int(c>>4) & 7
+	for c&0x80 != 0 {
+		if c, err = br.ReadByte(); err != nil {
+			return "", nil, err
+		}
+	}
+
+	switch typ {
+	case objCommit, objTree, objBlob, objTag:
+		data, err := inflate(br)
+		return objTypeNames[typ], data, err
+
+	case objOfsDelta:
+		// The base object sits a negative offset before this one
+		c, err := br.ReadByte()
+		if err != nil {
+			return "", nil, err
+		}
+		rel := int64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = br.ReadByte(); err != nil {
+				return "", nil, err
+			}
+			rel = ((rel + 1) << 7) | int64(c&0x7f)
+		}
+
+		delta, err := inflate(br)
+		if err != nil {
+			return "", nil, err
+		}
+		baseType, base, err := r.readPackedObject(p, offset-rel, depth+1)
+		if err != nil {
+			return "", nil, err
+		}
+		data, err := applyDelta(base, delta)
+		return baseType, data, err
+
+	case objRefDelta:
+		baseHash := make([]byte, 20)
+		if _, err := io.ReadFull(br, baseHash); err != nil {
+			return "", nil, err
+		}
+
+		delta, err := inflate(br)
+		if err != nil {
+			return "", nil, err
+		}
+		baseType, base, err := r.readObject(hex.EncodeToString(baseHash), depth+1)
+		if err != nil {
+			return "", nil, err
+		}
+		data, err := applyDelta(base, delta)
+		return baseType, data, err
+	}
+
+	return "", nil, fmt.Errorf("unknown object type %d in %s", typ, p.path)
+}
+
+func inflate(r io.Reader) ([]byte, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// applyDelta rebuilds an object from its base and a git delta.
+func applyDelta(base []byte, delta []byte) ([]byte, error) {
+	errCorrupt := errors.New("corrupt delta")
+
+	readSize := func() (int, bool) {
+		size, shift := 0, 0
+		for {
+			if len(delta) == 0 {
+				return 0, false
+			}
+			c := delta[0]
+			delta = delta[1:]
+			size |= int(c&0x7f) << shift
+			shift += 7
+			if c&0x80 == 0 {
+				return size, true
+			}
+		}
+	}
+
+	srcSize, ok := readSize()
+	if !ok || srcSize != len(base) {
+		return nil, errCorrupt
+	}
+	dstSize, ok := readSize()
+	if !ok {
+		return nil, errCorrupt
+	}
+
+	out := make([]byte, 0, dstSize)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+
+		switch {
+		case op&0x80 != 0:
+			// Copy a range from the base object
+			var offset, size int
+			for i := range 4 {
+				if op&(1<<i) != 0 {
+					if len(delta) == 0 {
+						return nil, errCorrupt
+					}
+					offset |= int(delta[0]) << (8 * i)
+					delta = delta[1:]
+				}
+			}
+			for i := range 3 {
+				if op&(0x10<<i) != 0 {
+					if len(delta) == 0 {
+						return nil, errCorrupt
+					}
+					size |= int(delta[0]) << (8 * i)
+					delta = delta[1:]
+				}
+			}
+			if size == 0 {
+				size = 0x10000
+			}
+			if offset+size > len(base) {
+				return nil, errCorrupt
+			}
+			out = append(out, base[offset:offset+size]...)
+
+		case op != 0:
+			// Insert the next op bytes
+			if int(op) > len(delta) {
+				return nil, errCorrupt
+			}
+			out = append(out, delta[:op]...)
+			delta = delta[op:]
+
+		default:
+			return nil, errCorrupt
+		}
+	}
+
+	if len(out) != dstSize {
+		return nil, errCorrupt
+	}
+	return out, nil
+}
@@ -0,0 +1,116 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrRefNotFound = errors.New("ref not found")
+
+// Symbolic refs can point to each other, this stops us from following a loop forever.
+const maxSymrefDepth = 10
+
+// Head returns the ref HEAD points to (e.g. "refs/heads/main") and the commit hash it resolves to.
+// For a detached HEAD the ref is empty.
+// The hash is empty when the branch has no commits yet.
+func (r *Repo) Head() (string, string, error) {
+	b, err := os.ReadFile(filepath.Join(r.GitDir, "HEAD"))
+	if err != nil {
+		return "", "", err
+	}
+
+	content := strings.TrimSpace(string(b))
+	ref, ok := strings.CutPrefix(content, "ref: ")
+	if !ok {
+		return "", content, nil // Detached HEAD
+	}
+
+	hash, err := r.ResolveRef(ref)
+	if errors.Is(err, ErrRefNotFound) {
+		return ref, "", nil // Unborn branch
+	}
+	return ref, hash, err
+}
+
+// ResolveRef follows a ref such as "refs/heads/main" to the hash it points to.
+func (r *Repo) ResolveRef(name string) (string, error) {
+	for range maxSymrefDepth {
+		content, err := r.readLooseRef(name)
+		if errors.Is(err, os.ErrNotExist) {
+			packed, err := r.PackedRefs()
+			if err != nil {
+				return "", err
+			}
+			if hash, ok := packed[name]; ok {
+				return hash, nil
+			}
+			return "", fmt.Errorf("%w: %s", ErrRefNotFound, name)
+		}
+		if err != nil {
+			return "", err
+		}
+
+		target, ok := strings.CutPrefix(content, "ref: ")
+		if !ok {
+			return content, nil
+		}
+		name = target
+	}
+	return "", fmt.Errorf("too many levels of symbolic refs: %s", name)
+}
+
+// readLooseRef reads a ref stored as a file.
+// Per-worktree refs live in GitDir, shared ones in CommonDir.
+func (r *Repo) readLooseRef(name string) (string, error) {
+	dirs := []string{r.GitDir}
+	if r.CommonDir != r.GitDir {
+		dirs = append(dirs, r.CommonDir)
+	}
+
+	for _, dir := range dirs {
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+	return "", os.ErrNotExist
+}
+
+// PackedRefs parses the packed-refs file into a map of ref name to hash.
+// A missing file is not an error, it just means no refs have been packed.
+func (r *Repo) PackedRefs() (map[string]string, error) {
+	refs := make(map[string]string)
+
+	f, err := os.Open(filepath.Join(r.CommonDir, "packed-refs"))
+	if errors.Is(err, os.ErrNotExist) {
+		return refs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Skip the header and the peeled hashes of annotated tags
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+
+		hash, name, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		refs[name] = hash
+	}
+	return refs, scanner.Err()
+}
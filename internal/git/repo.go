@@ -0,0 +1,87 @@
+/*
+	This package reads git repositories directly from the .git directory.
+	It does not need the git binary, only the parts sweepy cares about are implemented:
+	refs, packed-refs and loose or packed objects.
+*/
+
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrNotRepo = errors.New("not inside a git repository")
+
+type Repo struct {
+	WorkTree  string // Directory that holds the checked out files
+	GitDir    string // .git directory (or the per-worktree directory for linked worktrees)
+	CommonDir string // Directory holding objects and shared refs
+
+	packs []*pack // Lazily loaded pack indexes
+}
+
+// Open finds the repository that contains dir by walking up the directory tree.
+func Open(dir string) (*Repo, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		gitPath := filepath.Join(dir, ".git")
+		if info, err := os.Stat(gitPath); err == nil {
+			gitDir := gitPath
+			if !info.IsDir() {
+				// Worktrees and submodules use a .git file pointing to the real directory
+				gitDir, err = readGitFile(gitPath)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return newRepo(dir, gitDir), nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, ErrNotRepo
+		}
+		dir = parent
+	}
+}
+
+func newRepo(workTree string, gitDir string) *Repo {
+	commonDir := gitDir
+	if b, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(b))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+
+	return &Repo{
+		WorkTree:  workTree,
+		GitDir:    gitDir,
+		CommonDir: commonDir,
+	}
+}
+
+// readGitFile parses a `.git` file containing "gitdir: <path>".
+func readGitFile(p string) (string, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return "", err
+	}
+
+	line := strings.TrimSpace(string(b))
+	gitDir, ok := strings.CutPrefix(line, "gitdir: ")
+	if !ok {
+		return "", errors.New("invalid .git file: " + p)
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(p), gitDir)
+	}
+	return gitDir, nil
+}
@@ -0,0 +1,280 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// WorkStatus tells whether a repository holds work that only exists on this machine.
+type WorkStatus struct {
+	Dirty    bool // Staged or unstaged changes to tracked files
+	Unpushed bool // The current branch has commits its upstream does not have
+}
+
+// Status reads the work status of the repository containing dir.
+// Untracked files are not considered, that would require evaluating .gitignore rules.
+func Status(dir string) (WorkStatus, error) {
+	repo, err := Open(dir)
+	if err != nil {
+		return WorkStatus{}, err
+	}
+
+	dirty, err := repo.IsDirty()
+	if err != nil {
+		return WorkStatus{}, err
+	}
+
+	unpushed, err := repo.HasUnpushedCommits()
+	if err != nil {
+		return WorkStatus{}, err
+	}
+
+	return WorkStatus{Dirty: dirty, Unpushed: unpushed}, nil
+}
+
+// IsDirty compares the index against HEAD (staged changes) and the working tree (unstaged changes).
+func (r *Repo) IsDirty() (bool, error) {
+	entries, err := r.ReadIndex()
+	if err != nil {
+		return false, err
+	}
+
+	headFiles := map[string]string{}
+	_, hash, err := r.Head()
+	if err != nil {
+		return false, err
+	}
+	if hash != "" {
+		commit, err := r.ReadCommit(hash)
+		if err != nil {
+			return false, err
+		}
+		if err := r.readTree(commit.Tree, "", headFiles); err != nil {
+			return false, err
+		}
+	}
+
+	// Staged changes
+	if len(entries) != len(headFiles) {
+		return true, nil
+	}
+	for _, entry := range entries {
+		if entry.Stage != 0 || headFiles[entry.Path] != entry.Hash {
+			return true, nil
+		}
+	}
+
+	// Unstaged changes
+	for _, entry := range entries {
+		if entry.SkipWorktree || entry.Mode == modeGitlink {
+			continue
+		}
+		modified, err := r.isModified(entry)
+		if err != nil {
+			return false, err
+		}
+		if modified {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isModified checks a single index entry against the file in the working tree.
+// Like git, size and mtime are trusted when they match, otherwise the content is hashed.
+func (r *Repo) isModified(entry IndexEntry) (bool, error) {
+	p := filepath.Join(r.WorkTree, filepath.FromSlash(entry.Path))
+	info, err := os.Lstat(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil // Deleted
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if entry.Mode == modeSymlink {
+		target, err := os.Readlink(p)
+		if err != nil {
+			return true, nil // No longer a symlink
+		}
+		return hashBlob([]byte(filepath.ToSlash(target))) != entry.Hash, nil
+	}
+
+	if !info.Mode().IsRegular() {
+		return true, nil
+	}
+	if uint32(info.Size()) != entry.Size {
+		return true, nil
+	}
+	if info.ModTime().Equal(time.Unix(int64(entry.MtimeSeconds), int64(entry.MtimeNanos))) {
+		return false, nil
+	}
+
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return false, err
+	}
+	return hashBlob(content) != entry.Hash, nil
+}
+
+// readTree collects the blobs of a tree, recursively, into files keyed by path.
+func (r *Repo) readTree(hash string, prefix string, files map[string]string) error {
+	typ, data, err := r.ReadObject(hash)
+	if err != nil {
+		return err
+	}
+	if typ != "tree" {
+		return fmt.Errorf("object %s is a %s, not a tree", hash, typ)
+	}
+
+	// Tree entries are "<mode> <name>\x00<20 byte hash>"
+	for len(data) > 0 {
+		header, rest, ok := bytes.Cut(data, []byte{0})
+		if !ok || len(rest) < 20 {
+			return fmt.Errorf("corrupt tree %s", hash)
+		}
+		mode, name, _ := strings.Cut(string(header), " ")
+		entryHash := hex.EncodeToString(rest[:20])
+		data = rest[20:]
+
+		p := path.Join(prefix, name)
+		if mode == "40000" {
+			if err := r.readTree(entryHash, p, files); err != nil {
+				return err
+			}
+			continue
+		}
+		files[p] = entryHash
+	}
+	return nil
+}
+
+// HasUnpushedCommits reports whether the checked out branch has commits that are not on its upstream.
+// A branch without an upstream counts as unpushed when the repository has a remote to push to.
+func (r *Repo) HasUnpushedCommits() (bool, error) {
+	ref, hash, err := r.Head()
+	if err != nil {
+		return false, err
+	}
+	if hash == "" || ref == "" {
+		return false, nil // No commits, or a detached HEAD which has no upstream
+	}
+
+	config, err := r.Config()
+	if err != nil {
+		return false, err
+	}
+
+	branch := strings.TrimPrefix(ref, "refs/heads/")
+	remote := config[`branch "`+branch+`".remote`]
+	merge := config[`branch "`+branch+`".merge`]
+	if remote == "" || merge == "" {
+		for key := range config {
+			if strings.HasPrefix(key, "remote ") {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	upstreamRef := merge
+	if remote != "." {
+		upstreamRef = "refs/remotes/" + remote + "/" + strings.TrimPrefix(merge, "refs/heads/")
+	}
+	upstream, err := r.ResolveRef(upstreamRef)
+	if errors.Is(err, ErrRefNotFound) {
+		return true, nil // Never pushed, or the remote branch is gone
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if upstream == hash {
+		return false, nil
+	}
+	isAncestor, err := r.IsAncestor(hash, upstream)
+	return !isAncestor, err
+}
+
+// IsAncestor reports whether commit a is reachable from commit b.
+// Commits much older than a are not explored, which keeps long histories fast.
+// In the rare case of badly skewed clocks this can report false for a real ancestor.
+func (r *Repo) IsAncestor(a string, b string) (bool, error) {
+	target, err := r.ReadCommit(a)
+	if err != nil {
+		return false, err
+	}
+	cutoff := target.CommitterTime.Add(-24 * time.Hour)
+
+	visited := map[string]bool{b: true}
+	queue := []string{b}
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		if hash == a {
+			return true, nil
+		}
+
+		commit, err := r.ReadCommit(hash)
+		if err != nil {
+			return false, err
+		}
+		if commit.CommitterTime.Before(cutoff) {
+			continue
+		}
+		for _, parent := range commit.Parents {
+			if !visited[parent] {
+				visited[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+	return false, nil
+}
+
+// Config reads the repository config into a flat map.
+// Keys are "<section> <subsection>.<key>" as written in the file, e.g. `branch "main".remote`,
+// with the section and key names lower cased.
+func (r *Repo) Config() (map[string]string, error) {
+	config := make(map[string]string)
+
+	f, err := os.Open(filepath.Join(r.CommonDir, "config"))
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name, sub, hasSub := strings.Cut(line[1:len(line)-1], " ")
+			section = strings.ToLower(name)
+			if hasSub {
+				section += " " + strings.TrimSpace(sub)
+			}
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, "=")
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		config[section+"."+strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	return config, scanner.Err()
+}
@@ -0,0 +1,44 @@
+package project
+
+import (
+	"path/filepath"
+)
+
+// InstallCommand returns the command that reinstalls the node_modules of the project in dir,
+// and the directory to run it in. Workspace members are installed from their workspace root.
+// With a lockfile the command refuses to change it, so the tree matches what was deleted.
+func InstallCommand(dir string) (string, []string) {
+	if root := FindWorkspaceRoot(dir); root != "" {
+		dir = root
+	}
+
+	manager := ""
+	if meta, err := ReadMeta(dir); err == nil {
+		manager = meta.PackageManager
+	}
+	lockfile := FindLockfile(dir)
+	if manager == "" && lockfile != "" {
+		manager = lockfileManagers[filepath.Base(lockfile)]
+	}
+
+	if lockfile == "" {
+		if manager == "" {
+			manager = "npm"
+		}
+		return dir, []string{manager, "install"}
+	}
+
+	switch manager {
+	case "pnpm":
+		return dir, []string{"pnpm", "install", "--frozen-lockfile"}
+	case "yarn":
+		// Yarn 2+ renamed the flag, it is configured with .yarnrc.yml
+		if fileExists(filepath.Join(dir, ".yarnrc.yml")) {
+			return dir, []string{"yarn", "install", "--immutable"}
+		}
+		return dir, []string{"yarn", "install", "--frozen-lockfile"}
+	case "bun":
+		return dir, []string{"bun", "install", "--frozen-lockfile"}
+	}
+	return dir, []string{"npm", "ci"}
+}
@@ -0,0 +1,73 @@
+/*
+	This package reads what a JavaScript project says about itself:
+	its package.json and lockfiles, found next to the node_modules directory.
+*/
+
+package project
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/drxc00/sweepy/types"
+)
+
+// Lockfiles of the package managers we know, in the order they are checked.
+var lockfiles = []string{
+	"package-lock.json",
+	"npm-shrinkwrap.json",
+	"yarn.lock",
+	"pnpm-lock.yaml",
+	"bun.lockb",
+	"bun.lock",
+}
+
+// FindLockfile returns the path of the lockfile used by the project in dir, or "" if there is none.
+// Workspace members share the lockfile of their workspace root.
+func FindLockfile(dir string) string {
+	return NewScope(nil).FindLockfile(dir)
+}
+
+// FindLockfile is FindLockfile with the workspace root searched within the scope.
+func (s *Scope) FindLockfile(dir string) string {
+	if p := lockfileIn(dir); p != "" {
+		return p
+	}
+
+	if root := s.FindWorkspaceRoot(dir); root != "" && root != dir {
+		return lockfileIn(root)
+	}
+	return ""
+}
+
+func lockfileIn(dir string) string {
+	for _, name := range lockfiles {
+		p := filepath.Join(dir, name)
+		if fileExists(p) {
+			return p
+		}
+	}
+	return ""
+}
+
+// Classify tells whether the node_modules of the project in dir can be reinstalled exactly.
+// Without a package.json we cannot tell what the directory belongs to.
+func Classify(dir string) types.Reproducibility {
+	return NewScope(nil).Classify(dir)
+}
+
+// Classify is Classify with the workspace root searched within the scope.
+func (s *Scope) Classify(dir string) types.Reproducibility {
+	if !fileExists(filepath.Join(dir, "package.json")) {
+		return types.ReproducibilityUnknown
+	}
+	if s.FindLockfile(dir) == "" {
+		return types.ReproducibilityRisky
+	}
+	return types.ReproducibilityReproducible
+}
+
+func fileExists(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,33 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Files and directories that tell what kind of project a directory holds, besides the lockfiles.
+var markerFiles = []string{
+	"package.json",
+	".git",
+	".nvmrc",
+	".node-version",
+	".npmrc",
+	".yarnrc.yml",
+	"pnpm-workspace.yaml",
+	"tsconfig.json",
+	"turbo.json",
+	"nx.json",
+	"lerna.json",
+}
+
+// Markers returns the marker files and lockfiles present in dir.
+func Markers(dir string) []string {
+	var found []string
+	for _, name := range slices.Concat(markerFiles, lockfiles) {
+		if _, err := os.Lstat(filepath.Join(dir, name)); err == nil {
+			found = append(found, name)
+		}
+	}
+	return found
+}
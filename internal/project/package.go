@@ -0,0 +1,100 @@
+package project
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/drxc00/sweepy/types"
+)
+
+// Package managers implied by each lockfile
+var lockfileManagers = map[string]string{
+	"package-lock.json":   "npm",
+	"npm-shrinkwrap.json": "npm",
+	"yarn.lock":           "yarn",
+	"pnpm-lock.yaml":      "pnpm",
+	"bun.lockb":           "bun",
+	"bun.lock":            "bun",
+}
+
+// packageJSON holds the fields of package.json we care about.
+type packageJSON struct {
+	Name           string            `json:"name"`
+	Version        string            `json:"version"`
+	PackageManager string            `json:"packageManager"`
+	Engines        map[string]string `json:"engines"`
+	Workspaces     json.RawMessage   `json:"workspaces"`
+}
+
+func readPackageJSON(dir string) (packageJSON, error) {
+	var pkg packageJSON
+	b, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return pkg, err
+	}
+	err = json.Unmarshal(b, &pkg)
+	return pkg, err
+}
+
+// ReadMeta reads the metadata of the project in dir from its package.json.
+// The package manager comes from the "packageManager" field, or from the lockfile if it is not set.
+func ReadMeta(dir string) (types.ProjectMeta, error) {
+	return NewScope(nil).ReadMeta(dir)
+}
+
+// ReadMeta is ReadMeta with the workspace root searched within the scope.
+func (s *Scope) ReadMeta(dir string) (types.ProjectMeta, error) {
+	pkg, err := readPackageJSON(dir)
+	if err != nil {
+		return types.ProjectMeta{}, err
+	}
+
+	meta := types.ProjectMeta{
+		Name:       pkg.Name,
+		Version:    pkg.Version,
+		NodeEngine: pkg.Engines["node"],
+		Workspaces: parseWorkspaces(pkg.Workspaces),
+	}
+
+	// "pnpm@8.6.0+sha256.abc" -> "pnpm"
+	if pkg.PackageManager != "" {
+		meta.PackageManager, _, _ = strings.Cut(pkg.PackageManager, "@")
+	} else if lockfile := s.FindLockfile(dir); lockfile != "" {
+		meta.PackageManager = lockfileManagers[filepath.Base(lockfile)]
+	}
+
+	return meta, nil
+}
+
+// parseWorkspaces accepts both `"workspaces": ["packages/*"]`
+// and the yarn form `"workspaces": {"packages": ["packages/*"]}`.
+func parseWorkspaces(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var patterns []string
+	if err := json.Unmarshal(raw, &patterns); err == nil {
+		return patterns
+	}
+
+	var object struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(raw, &object); err == nil {
+		return object.Packages
+	}
+	return nil
+}
+
+// ReadPackage reads the name and version of the installed package in dir.
+// Unlike ReadMeta it does not look for lockfiles, it is cheap enough to call for every dependency.
+func ReadPackage(dir string) (name, version string, err error) {
+	pkg, err := readPackageJSON(dir)
+	if err != nil {
+		return "", "", err
+	}
+	return pkg.Name, pkg.Version, nil
+}
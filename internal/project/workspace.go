@@ -0,0 +1,185 @@
+package project
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// WorkspacePatterns returns the member patterns of the workspace rooted at dir,
+// from pnpm-workspace.yaml or the "workspaces" field of package.json.
+func WorkspacePatterns(dir string) []string {
+	if patterns, err := readPnpmWorkspace(filepath.Join(dir, "pnpm-workspace.yaml")); err == nil {
+		return patterns
+	}
+
+	pkg, err := readPackageJSON(dir)
+	if err != nil {
+		return nil
+	}
+	return parseWorkspaces(pkg.Workspaces)
+}
+
+// Scope bounds the search for workspace roots to the scan roots, a workspace above them
+// was not scanned and does not own the projects found. It remembers the patterns it read,
+// a scan asks about the same parent directories for every project under them.
+// It is safe for concurrent use.
+type Scope struct {
+	roots []string // Absolute, nil for no bound
+
+	mu       sync.Mutex
+	patterns map[string][]string
+}
+
+// NewScope returns a scope bounded to roots, or an unbounded one when roots is empty.
+func NewScope(roots []string) *Scope {
+	s := &Scope{patterns: make(map[string][]string)}
+	for _, root := range roots {
+		if abs, err := filepath.Abs(root); err == nil {
+			s.roots = append(s.roots, abs)
+		}
+	}
+	return s
+}
+
+// inRoots reports whether dir is one of the roots or under one.
+func (s *Scope) inRoots(dir string) bool {
+	if len(s.roots) == 0 {
+		return true
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	for _, root := range s.roots {
+		if rel, err := filepath.Rel(root, abs); err == nil && filepath.IsLocal(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// workspacePatterns is WorkspacePatterns, read once per directory.
+func (s *Scope) workspacePatterns(dir string) []string {
+	s.mu.Lock()
+	patterns, ok := s.patterns[dir]
+	s.mu.Unlock()
+	if ok {
+		return patterns
+	}
+
+	patterns = WorkspacePatterns(dir)
+	s.mu.Lock()
+	s.patterns[dir] = patterns
+	s.mu.Unlock()
+	return patterns
+}
+
+// FindWorkspaceRoot returns the root of the workspace the project in dir belongs to,
+// without looking above the roots of the scope.
+// A workspace root belongs to its own workspace. It returns "" when dir is not part of a workspace.
+func (s *Scope) FindWorkspaceRoot(dir string) string {
+	if len(s.workspacePatterns(dir)) > 0 {
+		return dir
+	}
+
+	for root := filepath.Dir(dir); root != filepath.Dir(root) && s.inRoots(root); root = filepath.Dir(root) {
+		patterns := s.workspacePatterns(root)
+		if len(patterns) == 0 {
+			continue
+		}
+
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			return ""
+		}
+		if matchWorkspace(patterns, filepath.ToSlash(rel)) {
+			return root
+		}
+		// A workspace root that does not list dir, nested projects are on their own
+		return ""
+	}
+	return ""
+}
+
+// FindWorkspaceRoot returns the root of the workspace the project in dir belongs to, wherever it is.
+// A workspace root belongs to its own workspace. It returns "" when dir is not part of a workspace.
+func FindWorkspaceRoot(dir string) string {
+	return NewScope(nil).FindWorkspaceRoot(dir)
+}
+
+// matchWorkspace applies the patterns in order, later "!pattern" entries exclude earlier matches.
+func matchWorkspace(patterns []string, rel string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		pattern = strings.TrimPrefix(pattern, "./")
+		if negated, ok := strings.CutPrefix(pattern, "!"); ok {
+			if matchGlob(strings.Split(strings.TrimPrefix(negated, "./"), "/"), strings.Split(rel, "/")) {
+				matched = false
+			}
+			continue
+		}
+		if matchGlob(strings.Split(strings.TrimSuffix(pattern, "/"), "/"), strings.Split(rel, "/")) {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// matchGlob matches path segments against pattern segments, "**" matches any number of segments.
+func matchGlob(pattern []string, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchGlob(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	ok, err := path.Match(pattern[0], segments[0])
+	return err == nil && ok && matchGlob(pattern[1:], segments[1:])
+}
+
+// readPnpmWorkspace reads the "packages" list of pnpm-workspace.yaml.
+// Only that list is needed, so this is not a full YAML parser.
+func readPnpmWorkspace(p string) ([]string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	inPackages := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// A new top level key ends the list
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, "-") {
+			inPackages = strings.HasPrefix(trimmed, "packages:")
+			continue
+		}
+
+		if item, ok := strings.CutPrefix(trimmed, "-"); ok && inPackages {
+			item, _, _ = strings.Cut(item, " #")
+			patterns = append(patterns, strings.Trim(strings.TrimSpace(item), `"'`))
+		}
+	}
+	return patterns, scanner.Err()
+}
@@ -0,0 +1,120 @@
+package scan
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/drxc00/sweepy/internal/project"
+	"github.com/drxc00/sweepy/types"
+)
+
+// PackageBreakdown lists the packages installed directly in the node_modules directory p,
+// biggest first. Scoped packages are listed as "@org/pkg", directories like ".pnpm" and ".cache"
+// are listed as they are since they can hold most of the bytes.
+// Each package also reports the copies of itself installed in nested node_modules directories.
+func PackageBreakdown(p string) ([]types.PackageSize, error) {
+	dirs, err := packageDirs(p)
+	if err != nil {
+		return nil, err
+	}
+
+	packages := make([]types.PackageSize, 0, len(dirs))
+	for _, name := range dirs {
+		pkg := types.PackageSize{Name: name, Path: filepath.Join(p, filepath.FromSlash(name))}
+
+		info, err := os.Lstat(pkg.Path)
+		if err != nil {
+			continue
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			pkg.IsSymlink = true
+		} else {
+			pkg.Size, _ = DirSizeFastWalk(pkg.Path)
+		}
+		_, pkg.Version, _ = project.ReadPackage(pkg.Path)
+		packages = append(packages, pkg)
+	}
+
+	// Count the nested copies, e.g. node_modules/a/node_modules/lodash
+	nested, err := nestedPackages(p)
+	if err != nil {
+		return nil, err
+	}
+	for i := range packages {
+		for _, copyPath := range nested[packages[i].Name] {
+			size, _ := DirSizeFastWalk(copyPath)
+			packages[i].Nested++
+			packages[i].NestedSize += size
+		}
+	}
+
+	slices.SortStableFunc(packages, func(a, b types.PackageSize) int {
+		switch {
+		case a.Size > b.Size:
+			return -1
+		case a.Size < b.Size:
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return packages, nil
+}
+
+// packageDirs returns the names of the packages in the node_modules directory p.
+// ".bin" only holds links to the packages' executables and is left out.
+func packageDirs(p string) ([]string, error) {
+	entries, err := os.ReadDir(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == ".bin" || (!entry.IsDir() && entry.Type()&os.ModeSymlink == 0) {
+			continue
+		}
+
+		if strings.HasPrefix(name, "@") && entry.IsDir() {
+			scoped, err := os.ReadDir(filepath.Join(p, name))
+			if err != nil {
+				continue
+			}
+			for _, s := range scoped {
+				if s.IsDir() || s.Type()&os.ModeSymlink != 0 {
+					names = append(names, name+"/"+s.Name())
+				}
+			}
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// nestedPackages maps package names to the paths of their copies in the node_modules
+// directories found below p. Symlinks are not followed.
+func nestedPackages(p string) (map[string][]string, error) {
+	nested := make(map[string][]string)
+	err := filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Unreadable directories are skipped
+		}
+		if !d.IsDir() || d.Name() != "node_modules" || path == p {
+			return nil
+		}
+
+		names, err := packageDirs(path)
+		if err != nil {
+			return nil
+		}
+		for _, name := range names {
+			nested[name] = append(nested[name], filepath.Join(path, filepath.FromSlash(name)))
+		}
+		return nil
+	})
+	return nested, err
+}
@@ -0,0 +1,72 @@
+package scan
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/charlievieth/fastwalk"
+	"github.com/drxc00/sweepy/internal/git"
+	"github.com/drxc00/sweepy/internal/project"
+	"github.com/drxc00/sweepy/types"
+	"github.com/drxc00/sweepy/utils"
+)
+
+// ArtifactDetails reads the details of the node_modules directory p:
+// its files and their size on disk, its biggest packages, and the project around it.
+func ArtifactDetails(p string) (types.ArtifactDetails, error) {
+	dir := filepath.Dir(p)
+	details := types.ArtifactDetails{Markers: project.Markers(dir)}
+
+	status, err := git.Status(dir)
+	if err == nil {
+		details.GitRepo = true
+		details.GitDirty = status.Dirty
+		details.GitUnpushed = status.Unpushed
+	} else if !errors.Is(err, git.ErrNotRepo) {
+		utils.Log("Error when reading git status: %v\n", err)
+	}
+
+	files, apparent, disk, err := countFiles(p)
+	if err != nil {
+		return details, err
+	}
+	details.Files, details.ApparentSize, details.DiskSize = files, apparent, disk
+
+	details.Packages, err = PackageBreakdown(p)
+	return details, err
+}
+
+// countFiles counts the regular files under p, with their apparent size and the space they take on disk.
+// Symlinks are not followed, like in DirSizeFastWalk.
+func countFiles(p string) (int64, int64, int64, error) {
+	var files, apparent, disk atomic.Int64 // fastwalk calls back from several goroutines
+
+	conf := fastwalk.DefaultConfig.Copy()
+	conf.Follow = false
+	err := fastwalk.Walk(conf, p, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrPermission) {
+				return fastwalk.SkipDir
+			}
+			return nil
+		}
+		if d == nil || !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		usage, ok := utils.DiskUsage(info)
+		if !ok {
+			usage = info.Size()
+		}
+		files.Add(1)
+		apparent.Add(info.Size())
+		disk.Add(usage)
+		return nil
+	})
+	return files.Load(), apparent.Load(), disk.Load(), err
+}
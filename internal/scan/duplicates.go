@@ -0,0 +1,134 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/drxc00/sweepy/internal/project"
+	"github.com/drxc00/sweepy/types"
+)
+
+// FindDuplicates indexes the packages installed directly in every scanned node_modules
+// by name and version, and returns those installed in more than one project, most wasted bytes first.
+// Symlinked packages (pnpm, npm link) already share their files and are not counted.
+func FindDuplicates(modules []types.ScannedNodeModule) []types.DuplicatePackage {
+	type key struct{ name, version string }
+	copies := make(map[key][]string) // package@version -> package directories
+	var order []key
+
+	for _, module := range modules {
+		if module.IsSymlink {
+			continue
+		}
+		dirs, err := packageDirs(module.Path)
+		if err != nil {
+			continue
+		}
+		for _, dir := range dirs {
+			p := filepath.Join(module.Path, filepath.FromSlash(dir))
+			if info, err := os.Lstat(p); err != nil || info.Mode()&os.ModeSymlink != 0 {
+				continue
+			}
+
+			name, version, err := project.ReadPackage(p)
+			if err != nil || version == "" {
+				continue // Not a package, e.g. .cache
+			}
+			if name == "" {
+				name = dir
+			}
+
+			k := key{name, version}
+			if _, ok := copies[k]; !ok {
+				order = append(order, k)
+			}
+			copies[k] = append(copies[k], p)
+		}
+	}
+
+	var duplicates []types.DuplicatePackage
+	for _, k := range order {
+		paths := copies[k]
+		if len(paths) < 2 {
+			continue
+		}
+
+		// Copies of the same version are assumed to weigh the same, measuring one is enough
+		size, _ := DirSizeFastWalk(paths[0])
+		projects := make([]string, 0, len(paths))
+		for _, p := range paths {
+			projects = append(projects, nodeModulesOf(p))
+		}
+		duplicates = append(duplicates, types.DuplicatePackage{
+			Name:     k.name,
+			Version:  k.version,
+			Size:     size,
+			Wasted:   size * int64(len(paths)-1),
+			Projects: projects,
+		})
+	}
+
+	slices.SortStableFunc(duplicates, func(a, b types.DuplicatePackage) int {
+		switch {
+		case a.Wasted > b.Wasted:
+			return -1
+		case a.Wasted < b.Wasted:
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return duplicates
+}
+
+// SharedStoreCandidates ranks the projects by the bytes of their duplicated packages.
+// Projects already installed by pnpm use a shared store and are left out.
+func SharedStoreCandidates(modules []types.ScannedNodeModule, duplicates []types.DuplicatePackage) []types.SharedStoreCandidate {
+	byPath := make(map[string]*types.SharedStoreCandidate)
+	var candidates []*types.SharedStoreCandidate
+	for _, module := range modules {
+		if module.Project.PackageManager == "pnpm" || module.IsSymlink {
+			continue
+		}
+		c := &types.SharedStoreCandidate{Path: module.Path, PackageManager: module.Project.PackageManager}
+		byPath[module.Path] = c
+		candidates = append(candidates, c)
+	}
+
+	for _, d := range duplicates {
+		for _, p := range d.Projects {
+			if c, ok := byPath[p]; ok {
+				c.Duplicated++
+				c.Size += d.Size
+			}
+		}
+	}
+
+	var result []types.SharedStoreCandidate
+	for _, c := range candidates {
+		if c.Duplicated > 0 {
+			result = append(result, *c)
+		}
+	}
+	slices.SortStableFunc(result, func(a, b types.SharedStoreCandidate) int {
+		switch {
+		case a.Size > b.Size:
+			return -1
+		case a.Size < b.Size:
+			return 1
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+	return result
+}
+
+// nodeModulesOf returns the node_modules directory a package directory is installed in,
+// "node_modules/@org/pkg" -> "node_modules".
+func nodeModulesOf(p string) string {
+	dir := filepath.Dir(p)
+	if strings.HasPrefix(filepath.Base(dir), "@") {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
@@ -0,0 +1,188 @@
+package scan
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/charlievieth/fastwalk"
+	"github.com/drxc00/sweepy/internal/git"
+	"github.com/drxc00/sweepy/types"
+	"github.com/drxc00/sweepy/utils"
+)
+
+// GitScan finds the repositories under ctx.Path and lists their local branches that are
+// merged into the default branch, or whose last commit is older than ctx.Staleness days.
+// A staleness of 0 only reports merged branches.
+func GitScan(ctx types.ScanContext, ch chan<- string) ([]types.StaleBranch, error) {
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	var branches []types.StaleBranch = []types.StaleBranch{}
+
+	startTime := time.Now()
+
+	repos, err := FindGitRepos(ctx.Path)
+	if err != nil {
+		close(ch)
+		utils.Log("Error after scanning: %v\n", err)
+		return []types.StaleBranch{}, err
+	}
+
+	for _, workTree := range uniqueRepos(repos) {
+		ch <- fmt.Sprintf("Scanning %s", workTree)
+
+		wg.Add(1)
+		go func(workTree string) {
+			defer wg.Done()
+
+			found, err := repoBranches(workTree, ctx.Staleness, startTime)
+			if err != nil {
+				utils.Log("Error reading branches of %s: %v\n", workTree, err)
+				ch <- fmt.Sprintf("Error reading branches of %s: %v", workTree, err)
+				return
+			}
+
+			mutex.Lock()
+			branches = append(branches, found...)
+			mutex.Unlock()
+		}(workTree)
+	}
+
+	wg.Wait()
+	close(ch)
+
+	// Goroutines finish in any order, keep the output stable
+	sort.Slice(branches, func(i, j int) bool {
+		if branches[i].Repo != branches[j].Repo {
+			return branches[i].Repo < branches[j].Repo
+		}
+		return branches[i].Name < branches[j].Name
+	})
+
+	return branches, nil
+}
+
+// FindGitRepos returns the work trees of the git repositories under root.
+func FindGitRepos(root string) ([]string, error) {
+	var mutex sync.Mutex
+	var repos []string = []string{}
+
+	walkFn := fastwalk.IgnorePermissionErrors(func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fastwalk.SkipDir
+		}
+
+		// Dependencies can contain repositories of their own, they are not ours to clean
+		if d.IsDir() && d.Name() == "node_modules" {
+			return fastwalk.SkipDir
+		}
+
+		// .git is a directory, or a file for worktrees and submodules
+		if d.Name() != ".git" {
+			return nil
+		}
+
+		mutex.Lock()
+		repos = append(repos, filepath.Dir(p))
+		mutex.Unlock()
+
+		if d.IsDir() {
+			return fastwalk.SkipDir
+		}
+		return nil
+	})
+
+	err := fastwalk.Walk(&fastwalk.DefaultConfig, root, walkFn)
+	sort.Strings(repos)
+	return repos, err
+}
+
+// uniqueRepos keeps one work tree per repository. Linked worktrees share the branches of
+// their repository, listing each of them would offer every branch more than once.
+// The main work tree is kept when it is under the root, else the first linked one.
+func uniqueRepos(workTrees []string) []string {
+	kept := make(map[string]int) // CommonDir to index in unique
+	var unique []string
+	for _, workTree := range workTrees {
+		repo, err := git.Open(workTree)
+		if err != nil {
+			unique = append(unique, workTree) // repoBranches reports the error
+			continue
+		}
+		i, ok := kept[repo.CommonDir]
+		if !ok {
+			kept[repo.CommonDir] = len(unique)
+			unique = append(unique, workTree)
+			continue
+		}
+		if repo.GitDir == repo.CommonDir {
+			unique[i] = workTree
+		}
+	}
+	return unique
+}
+
+// repoBranches returns the deletable branches of a single repository.
+// The default branch and the branches checked out in any worktree are never candidates.
+func repoBranches(workTree string, staleness int64, now time.Time) ([]types.StaleBranch, error) {
+	repo, err := git.Open(workTree)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := repo.Branches()
+	if err != nil {
+		return nil, err
+	}
+
+	checkedOut, err := repo.CheckedOutBranches()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultName, defaultHash, err := repo.DefaultBranch()
+	if err != nil && !errors.Is(err, git.ErrRefNotFound) {
+		return nil, err
+	}
+
+	var found []types.StaleBranch
+	for name, hash := range all {
+		if name == defaultName || checkedOut["refs/heads/"+name] {
+			continue
+		}
+
+		commit, err := repo.ReadCommit(hash)
+		if err != nil {
+			return nil, err
+		}
+
+		merged := false
+		if defaultHash != "" {
+			merged, err = repo.IsAncestor(hash, defaultHash)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		daysSinceCommit := int64(now.Sub(commit.CommitterTime).Hours() / 24)
+		stale := staleness != 0 && daysSinceCommit >= staleness
+		if !merged && !stale {
+			continue
+		}
+
+		found = append(found, types.StaleBranch{
+			Repo:          workTree,
+			Name:          name,
+			Hash:          hash,
+			LastCommit:    commit.CommitterTime,
+			Staleness:     daysSinceCommit,
+			Merged:        merged,
+			DefaultBranch: defaultName,
+		})
+	}
+	return found, nil
+}
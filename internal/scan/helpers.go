@@ -7,12 +7,19 @@ import (
 	"time"
 
 	"github.com/charlievieth/fastwalk"
+	"github.com/drxc00/sweepy/internal/git"
+	"github.com/drxc00/sweepy/types"
 )
 
+// DirSizeFastWalk sums the size of the regular files under path.
+// Symlinks are never followed, so bytes behind links outside the artifact are not counted.
 func DirSizeFastWalk(path string) (int64, error) {
 	var totalSize int64
 
-	err := fastwalk.Walk(&fastwalk.DefaultConfig, path, func(p string, d fs.DirEntry, err error) error {
+	conf := fastwalk.DefaultConfig.Copy()
+	conf.Follow = false
+
+	err := fastwalk.Walk(conf, path, func(p string, d fs.DirEntry, err error) error {
 		if err != nil {
 			// Skip permission errors silently
 			if errors.Is(err, fs.ErrPermission) {
@@ -22,8 +29,8 @@ func DirSizeFastWalk(path string) (int64, error) {
 			return nil
 		}
 
-		if d != nil && !d.IsDir() {
-			// Get file info for all non-directory entries
+		if d != nil && d.Type().IsRegular() {
+			// Get file info for all regular files
 			info, err := d.Info()
 			if err == nil {
 				totalSize += info.Size()
@@ -54,6 +61,15 @@ func DirSize(path string) (int64, error) {
 	return totalSize, err
 }
 
+// isSymlinkToDir reports whether the entry is a symlink that resolves to a directory.
+func isSymlinkToDir(p string, d fs.DirEntry) bool {
+	if d.Type()&fs.ModeSymlink == 0 {
+		return false
+	}
+	info, err := fastwalk.StatDirEntry(p, d)
+	return err == nil && info.IsDir()
+}
+
 func GetLastModified(p string) (time.Time, error) {
 	// Accepts a directory path `p` as input.
 	// This directory path is assumed as the parent directory of the node_modules directory.
@@ -76,3 +92,28 @@ func GetLastModified(p string) (time.Time, error) {
 
 	return lastModified, err
 }
+
+// LastActivity returns when the project in directory p was last worked on according to source,
+// along with the checked out git ref when git was consulted.
+// File mtimes are reset by checkouts, editors and formatters, the last commit is not.
+func LastActivity(p string, source string) (time.Time, string, error) {
+	if source != types.StalenessSourceGit && source != types.StalenessSourceMax {
+		lastModified, err := GetLastModified(p)
+		return lastModified, "", err
+	}
+
+	commitTime, ref, gitErr := git.LastCommit(p)
+	if source == types.StalenessSourceGit && gitErr == nil {
+		return commitTime, ref, nil
+	}
+
+	// Not a git repository (or no commits yet), mtime is all we have.
+	lastModified, err := GetLastModified(p)
+	if err != nil {
+		return lastModified, ref, err
+	}
+	if gitErr == nil && commitTime.After(lastModified) {
+		return commitTime, ref, nil
+	}
+	return lastModified, ref, nil
+}
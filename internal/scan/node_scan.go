@@ -1,6 +1,7 @@
 package scan
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
 	"log"
@@ -11,6 +12,8 @@ import (
 
 	"github.com/charlievieth/fastwalk"
 	"github.com/drxc00/sweepy/internal/cache"
+	"github.com/drxc00/sweepy/internal/git"
+	"github.com/drxc00/sweepy/internal/project"
 	"github.com/drxc00/sweepy/types"
 	"github.com/drxc00/sweepy/utils"
 )
@@ -42,12 +45,19 @@ func NodeScan(ctx types.ScanContext, ch chan<- string) ([]types.ScannedNodeModul
 	if cacheLoaded && !cache.IsExpired() && !ctx.NoCache && !ctx.ResetCache {
 
 		// Filter cached entries based on staleness criteria
+		sourceChanged := false
 		for p, module := range cache.Data {
 			// Check if the path contains the current path
 			if !strings.Contains(p, ctx.Path) {
 				continue
 			}
 
+			// Measured from another source, the whole root is scanned again
+			if cachedStalenessSource(module) != stalenessSource(ctx) {
+				sourceChanged = true
+				break
+			}
+
 			if ctx.Staleness != 0 && module.Staleness < ctx.Staleness {
 				continue
 			}
@@ -56,6 +66,7 @@ func NodeScan(ctx types.ScanContext, ch chan<- string) ([]types.ScannedNodeModul
 			ch <- fmt.Sprintf("Found %s in cache", module.Path)
 
 			// Add the module to the slice of scannedNodeModules
+			module.FromCache = true
 			mutex.Lock()
 			totalSize += module.Size
 			totalStaleness += float64(module.Staleness)
@@ -63,6 +74,11 @@ func NodeScan(ctx types.ScanContext, ch chan<- string) ([]types.ScannedNodeModul
 			mutex.Unlock()
 		}
 
+		if sourceChanged {
+			scannedNodeModules = []types.ScannedNodeModule{}
+			totalSize, totalStaleness = 0, 0
+		}
+
 		// If we have entries from cache and don't need a full rescan, return early
 		if len(scannedNodeModules) > 0 && !ctx.NoCache {
 			scanDuration := time.Since(startTime)
@@ -70,6 +86,9 @@ func NodeScan(ctx types.ScanContext, ch chan<- string) ([]types.ScannedNodeModul
 		}
 	}
 
+	// Workspace roots are only looked for inside the scan, and read once per directory
+	scope := project.NewScope(ctx.Roots())
+
 	// Fastwalk is a faster alternative to filepath.Walk
 	// Wraps our walk function to ignore permission errors
 	walkFn := fastwalk.IgnorePermissionErrors(func(p string, d fs.DirEntry, err error) error {
@@ -85,21 +104,21 @@ func NodeScan(ctx types.ScanContext, ch chan<- string) ([]types.ScannedNodeModul
 			return nil
 		}
 
-		// If the path is a directory and it's named "node_modules"
-		if d.IsDir() && d.Name() == "node_modules" {
+		// If the path is a directory (or a link to one) and it's named "node_modules"
+		if d.Name() == "node_modules" && (d.IsDir() || isSymlinkToDir(p, d)) {
 
 			ch <- fmt.Sprintf("Scanning %s", p)
 
 			wg.Add(1) // Add to the wait group
 
-			go func(nodeModulePath string) {
+			go func(nodeModulePath string, isSymlink bool) {
 				defer wg.Done()
 
 				// Get the last modified and accessed times of the directory containing the node_modules directory
 				// We do this so that we can know if the project has been updated since the last time we scanned it
 				// If we based it on the node_modules folder alone, it will not be accurate.
 				parentDir := filepath.Dir(nodeModulePath)
-				lastModified, lerr := GetLastModified(parentDir)
+				lastModified, gitRef, lerr := LastActivity(parentDir, ctx.StalenessSource)
 
 				if lerr != nil {
 					utils.Log("Error when determining last modified: %v\n", lerr)
@@ -114,11 +133,26 @@ func NodeScan(ctx types.ScanContext, ch chan<- string) ([]types.ScannedNodeModul
 					return
 				}
 
+				// Someone might be actively debugging this project, the TUI warns before deleting it.
+				workStatus, gerr := git.Status(parentDir)
+				if gerr != nil && !errors.Is(gerr, git.ErrNotRepo) {
+					utils.Log("Error when reading git status: %v\n", gerr)
+				}
+
+				// A missing or broken package.json just leaves the metadata empty
+				meta, _ := scope.ReadMeta(parentDir)
+
 				// Get the size of the node_modules directory
-				dirSize, err := DirSizeFastWalk(nodeModulePath)
-				if err != nil {
-					ch <- fmt.Sprintf("Error when calculating dir size: %v\n", err)
-					return
+				// A symlinked node_modules (npm link, workspaces) owns no bytes of its own,
+				// they belong to the link target which is outside the artifact.
+				var dirSize int64
+				if !isSymlink {
+					var err error
+					dirSize, err = DirSizeFastWalk(nodeModulePath)
+					if err != nil {
+						ch <- fmt.Sprintf("Error when calculating dir size: %v\n", err)
+						return
+					}
 				}
 
 				// Add for stats
@@ -132,11 +166,20 @@ func NodeScan(ctx types.ScanContext, ch chan<- string) ([]types.ScannedNodeModul
 					Size:         dirSize,
 					LastModified: lastModified,
 					Staleness:    daysSinceModified,
+					IsSymlink:    isSymlink,
+					GitRef:       gitRef,
+					GitDirty:     workStatus.Dirty,
+					GitUnpushed:  workStatus.Unpushed,
+
+					StalenessSource: stalenessSource(ctx),
+					Reproducibility: scope.Classify(parentDir),
+					Project:         meta,
+					Workspace:       scope.FindWorkspaceRoot(parentDir),
 				}
 				scannedNodeModules = append(scannedNodeModules, scannedNodeModule)
 				cache.Set(nodeModulePath, scannedNodeModule) // Save to cache handler
 				mutex.Unlock()
-			}(p)
+			}(p, d.Type()&fs.ModeSymlink != 0)
 
 			// If a node_modules directory is found, stop walking the directory tree
 			return fastwalk.SkipDir
@@ -145,6 +188,12 @@ func NodeScan(ctx types.ScanContext, ch chan<- string) ([]types.ScannedNodeModul
 		return nil
 	})
 
+	// Following links lets us find projects reached through `npm link` or workspace symlinks.
+	// IgnoreDuplicateDirs skips directories we have already been in, which also breaks link loops.
+	if ctx.FollowSymlinks {
+		walkFn = fastwalk.IgnoreDuplicateDirs(walkFn)
+	}
+
 	err := fastwalk.Walk(&fastwalk.DefaultConfig, ctx.Path, walkFn)
 
 	// Wait for all goroutines to finish
@@ -187,3 +236,20 @@ func NodeScan(ctx types.ScanContext, ch chan<- string) ([]types.ScannedNodeModul
 
 	return scannedNodeModules, types.ScanInfo{TotalSize: totalSize, AvgStaleness: avgStaleness, ScanDuration: scanDuration}, nil
 }
+
+// stalenessSource is the source the scan measures staleness from, mtime when none is set.
+func stalenessSource(ctx types.ScanContext) string {
+	if ctx.StalenessSource == "" {
+		return types.StalenessSourceMtime
+	}
+	return ctx.StalenessSource
+}
+
+// cachedStalenessSource is the source a cached entry was measured from.
+// Caches written before the source could be chosen were all measured from mtime.
+func cachedStalenessSource(module types.ScannedNodeModule) string {
+	if module.StalenessSource == "" {
+		return types.StalenessSourceMtime
+	}
+	return module.StalenessSource
+}
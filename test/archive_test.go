@@ -0,0 +1,265 @@
+package test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/drxc00/sweepy/internal/clean"
+)
+
+func TestArchiveAndRestore(t *testing.T) {
+	t.Chdir(t.TempDir())
+	tempJournal(t)
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"app/package.json":                         "{}",
+		"app/node_modules/lodash/index.js":         "module.exports = 'lodash'",
+		"app/node_modules/@org/ui/index.js":        "export default {}",
+		"app/node_modules/a/node_modules/b/b.json": `{"nested": true}`,
+	})
+	nm := filepath.Join(dir, "app", "node_modules")
+	if err := os.MkdirAll(filepath.Join(nm, ".bin"), 0755); err != nil {
+		t.Fatalf("Failed to create .bin: %v", err)
+	}
+	if err := os.Symlink("../lodash/index.js", filepath.Join(nm, ".bin", "lodash")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	if err := os.Chmod(filepath.Join(nm, "lodash", "index.js"), 0755); err != nil {
+		t.Fatalf("Failed to chmod: %v", err)
+	}
+
+	archive, err := clean.ArchiveNodeModule(nm, []string{dir}, "")
+	if err != nil {
+		t.Fatalf("ArchiveNodeModule failed: %v", err)
+	}
+	if archive != nm+clean.ArchiveExt {
+		t.Errorf("Expected the archive next to the project, got %s", archive)
+	}
+	if _, err := os.Lstat(nm); !os.IsNotExist(err) {
+		t.Fatalf("Expected node_modules to be removed after archiving, got %v", err)
+	}
+
+	// Archiving again must not overwrite the archive
+	if err := os.MkdirAll(nm, 0755); err != nil {
+		t.Fatalf("Failed to create node_modules: %v", err)
+	}
+	if _, err := clean.ArchiveNodeModule(nm, []string{dir}, ""); !errors.Is(err, clean.ErrArchiveExists) {
+		t.Errorf("Expected ErrArchiveExists, got %v", err)
+	}
+
+	// Restoring must not overwrite the node_modules that is there now
+	if _, err := clean.RestoreArchive(archive, nil); !errors.Is(err, clean.ErrRestoreExists) {
+		t.Errorf("Expected ErrRestoreExists, got %v", err)
+	}
+	if err := os.Remove(nm); err != nil {
+		t.Fatalf("Failed to remove node_modules: %v", err)
+	}
+
+	target, err := clean.RestoreArchive(archive, nil)
+	if err != nil {
+		t.Fatalf("RestoreArchive failed: %v", err)
+	}
+	if target != nm {
+		t.Errorf("Expected to restore to %s, got %s", nm, target)
+	}
+
+	files := map[string]string{
+		"lodash/index.js":         "module.exports = 'lodash'",
+		"@org/ui/index.js":        "export default {}",
+		"a/node_modules/b/b.json": `{"nested": true}`,
+		".bin/lodash":             "module.exports = 'lodash'", // Through the symlink
+	}
+	for name, expected := range files {
+		b, err := os.ReadFile(filepath.Join(nm, name))
+		if err != nil {
+			t.Errorf("Failed to read restored %s: %v", name, err)
+			continue
+		}
+		if string(b) != expected {
+			t.Errorf("Expected %s to contain %q, got %q", name, expected, b)
+		}
+	}
+
+	if info, err := os.Lstat(filepath.Join(nm, ".bin", "lodash")); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Expected .bin/lodash to be restored as a symlink")
+	}
+	if info, err := os.Stat(filepath.Join(nm, "lodash", "index.js")); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("Expected the file mode to be restored")
+	}
+}
+
+// tarEntry is an entry of a hand written archive.
+type tarEntry struct {
+	name, link, body string
+}
+
+// writeTarGz writes entries to a gzipped tarball with comment as the origin, the way sweepy stores it.
+func writeTarGz(t *testing.T, archive, comment string, entries []tarEntry) {
+	t.Helper()
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	gz.Comment = comment
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(e.body))}
+		switch {
+		case strings.HasSuffix(e.name, "/"):
+			header.Typeflag, header.Mode = tar.TypeDir, 0755
+		case e.link != "":
+			header.Typeflag, header.Linkname = tar.TypeSymlink, e.link
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to write header: %v", err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.body)); err != nil {
+				t.Fatalf("Failed to write %s: %v", e.name, err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Failed to close tar: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("Failed to close gzip: %v", err)
+	}
+}
+
+func TestRestoreArchiveMaliciousSymlinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+	}{
+		{
+			name: "link climbing out",
+			entries: []tarEntry{
+				{name: "node_modules/"},
+				{name: "node_modules/evil", link: "../../../outside"},
+				{name: "node_modules/evil/pwned", body: "pwned"},
+			},
+		},
+		{
+			name: "absolute link",
+			entries: []tarEntry{
+				{name: "node_modules/"},
+				{name: "node_modules/evil", link: "OUTSIDE"},
+				{name: "node_modules/evil/pwned", body: "pwned"},
+			},
+		},
+		{
+			name: "climbing after a link",
+			entries: []tarEntry{
+				{name: "node_modules/"},
+				{name: "node_modules/self", link: "."},
+				{name: "node_modules/evil", link: "self/../../../outside"},
+				{name: "node_modules/evil/pwned", body: "pwned"},
+			},
+		},
+		{
+			name: "link to a link",
+			entries: []tarEntry{
+				{name: "node_modules/"},
+				{name: "node_modules/a/"},
+				{name: "node_modules/a/up", link: ".."},
+				{name: "node_modules/evil", link: "a/up/../../../outside"},
+				{name: "node_modules/evil/pwned", body: "pwned"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			outside := filepath.Join(dir, "outside")
+			if err := os.MkdirAll(outside, 0755); err != nil {
+				t.Fatalf("Failed to create outside: %v", err)
+			}
+			project := filepath.Join(dir, "app")
+			if err := os.MkdirAll(project, 0755); err != nil {
+				t.Fatalf("Failed to create project: %v", err)
+			}
+
+			entries := make([]tarEntry, len(tt.entries))
+			for i, e := range tt.entries {
+				if e.link == "OUTSIDE" {
+					e.link = outside
+				}
+				entries[i] = e
+			}
+			nm := filepath.Join(project, "node_modules")
+			archive := nm + clean.ArchiveExt
+			writeTarGz(t, archive, nm, entries)
+
+			if _, err := clean.RestoreArchive(archive, nil); err == nil {
+				t.Errorf("Expected the archive to be refused")
+			}
+			filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+				if err == nil && d.Name() == "pwned" {
+					t.Errorf("Expected nothing written through the link, found %s", path)
+				}
+				return nil
+			})
+			if _, err := os.Lstat(nm); !os.IsNotExist(err) {
+				t.Errorf("Expected no node_modules after a refused restore, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRestoreArchiveMaliciousOrigin(t *testing.T) {
+	dir := t.TempDir()
+	archives := filepath.Join(dir, "archives")
+	project := filepath.Join(dir, "projects", "app")
+	elsewhere := filepath.Join(dir, "elsewhere")
+	for _, d := range []string{archives, project, elsewhere} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", d, err)
+		}
+	}
+	entries := []tarEntry{{name: "node_modules/"}, {name: "node_modules/a.js", body: "a"}}
+
+	tests := []struct {
+		name     string
+		origin   string
+		roots    []string
+		expected error
+	}{
+		{name: "not node_modules", origin: filepath.Join(elsewhere, ".ssh"), expected: clean.ErrNotArtifact},
+		{name: "not next to the archive", origin: filepath.Join(elsewhere, "node_modules"), expected: clean.ErrOutsideRoots},
+		{name: "outside the roots", origin: filepath.Join(elsewhere, "node_modules"), roots: []string{filepath.Join(dir, "projects")}, expected: clean.ErrOutsideRoots},
+		{name: "under a root", origin: filepath.Join(project, "node_modules"), roots: []string{filepath.Join(dir, "projects")}},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := filepath.Join(archives, fmt.Sprintf("%d%s", i, clean.ArchiveExt))
+			writeTarGz(t, archive, tt.origin, entries)
+
+			target, err := clean.RestoreArchive(archive, tt.roots)
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, err)
+			}
+			if tt.expected != nil {
+				if _, err := os.Lstat(tt.origin); !os.IsNotExist(err) {
+					t.Errorf("Expected nothing restored to %s, got %v", tt.origin, err)
+				}
+				return
+			}
+			if b, err := os.ReadFile(filepath.Join(target, "a.js")); err != nil || string(b) != "a" {
+				t.Errorf("Expected a.js restored under %s, got %q, %v", target, b, err)
+			}
+		})
+	}
+}
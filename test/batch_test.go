@@ -0,0 +1,59 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drxc00/sweepy/internal/clean"
+)
+
+func TestBatchClean(t *testing.T) {
+	t.Chdir(t.TempDir())
+	tempJournal(t)
+
+	dir := t.TempDir()
+	files := make(map[string]string)
+	var paths []string
+	for _, project := range []string{"a", "b", "c", "d", "e"} {
+		files[project+"/node_modules/pkg/index.js"] = "0123456789"
+		files[project+"/node_modules/pkg/lib/util.js"] = "01234"
+		paths = append(paths, filepath.Join(dir, project, "node_modules"))
+	}
+	writeFiles(t, dir, files)
+	paths = append(paths, filepath.Join(dir, "a")) // Not an artifact, must fail
+
+	progress := make(chan clean.BatchProgress, 100)
+	go func() {
+		clean.BatchClean(paths, []string{dir}, 2, progress)
+		close(progress)
+	}()
+
+	done := make(map[string]clean.BatchProgress)
+	for p := range progress {
+		if p.Done {
+			if _, ok := done[p.Path]; ok {
+				t.Errorf("%s reported done twice", p.Path)
+			}
+			done[p.Path] = p
+		}
+	}
+
+	if len(done) != len(paths) {
+		t.Fatalf("Expected %d directories to be done, got %d", len(paths), len(done))
+	}
+	for _, p := range paths[:5] {
+		if done[p].Err != nil {
+			t.Errorf("Expected %s to be removed, got %v", p, done[p].Err)
+		}
+		if done[p].Files != 2 || done[p].Bytes != 15 {
+			t.Errorf("Expected 2 files and 15 bytes for %s, got %d and %d", p, done[p].Files, done[p].Bytes)
+		}
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be gone", p)
+		}
+	}
+	if done[paths[5]].Err == nil {
+		t.Errorf("Expected %s to be refused", paths[5])
+	}
+}
@@ -0,0 +1,105 @@
+package test
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/drxc00/sweepy/internal/scan"
+)
+
+func TestPackageBreakdown(t *testing.T) {
+	dir := t.TempDir()
+	nm := filepath.Join(dir, "node_modules")
+	writeFiles(t, nm, map[string]string{
+		".bin/tool":                                  "#!/bin/sh",
+		"small/package.json":                         `{"name": "small", "version": "1.0.0"}`,
+		"big/package.json":                           `{"name": "big", "version": "2.0.0"}`,
+		"big/index.js":                               "0123456789012345678901234567890123456789",
+		"big/node_modules/small/package.json":        `{"name": "small", "version": "0.9.0"}`,
+		"@scope/pkg/package.json":                    `{"name": "@scope/pkg", "version": "3.1.0"}`,
+		"@scope/pkg/node_modules/small/package.json": `{"name": "small", "version": "0.8.0"}`,
+	})
+
+	packages, err := scan.PackageBreakdown(nm)
+	if err != nil {
+		t.Fatalf("PackageBreakdown failed: %v", err)
+	}
+
+	var names []string
+	for _, pkg := range packages {
+		names = append(names, pkg.Name)
+	}
+	expected := []string{"big", "@scope/pkg", "small"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected packages %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("Expected packages %v sorted by size, got %v", expected, names)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		version string
+		nested  int
+	}{
+		{name: "big", version: "2.0.0", nested: 0},
+		{name: "@scope/pkg", version: "3.1.0", nested: 0},
+		{name: "small", version: "1.0.0", nested: 2},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg := packages[i]
+			if pkg.Version != tt.version {
+				t.Errorf("Expected version %s, got %s", tt.version, pkg.Version)
+			}
+			if pkg.Nested != tt.nested {
+				t.Errorf("Expected %d nested copies, got %d", tt.nested, pkg.Nested)
+			}
+			if tt.nested > 0 && pkg.NestedSize == 0 {
+				t.Errorf("Expected the nested copies to have a size")
+			}
+		})
+	}
+}
+
+func TestArtifactDetails(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"package.json":                 `{"name": "app"}`,
+		"yarn.lock":                    "",
+		".nvmrc":                       "20",
+		"node_modules/a/package.json":  `{"name": "a"}`,
+		"node_modules/a/index.js":      "0123456789",
+		"node_modules/b/package.json":  `{"name": "b"}`,
+		"node_modules/b/lib/index.js":  "01234567890123456789",
+		"node_modules/b/lib/helper.js": "0123456789",
+	})
+
+	details, err := scan.ArtifactDetails(filepath.Join(dir, "node_modules"))
+	if err != nil {
+		t.Fatalf("ArtifactDetails failed: %v", err)
+	}
+
+	if details.Files != 5 {
+		t.Errorf("Expected 5 files, got %d", details.Files)
+	}
+	if want := int64(2*len(`{"name": "a"}`) + 40); details.ApparentSize != want {
+		t.Errorf("Expected an apparent size of %d, got %d", want, details.ApparentSize)
+	}
+	if details.DiskSize <= 0 {
+		t.Errorf("Expected a disk size, got %d", details.DiskSize)
+	}
+	if !slices.Equal(details.Markers, []string{"package.json", ".nvmrc", "yarn.lock"}) {
+		t.Errorf("Expected the markers package.json, .nvmrc and yarn.lock, got %v", details.Markers)
+	}
+	if details.GitRepo {
+		t.Errorf("Expected no git repository")
+	}
+	if len(details.Packages) != 2 || details.Packages[0].Name != "b" {
+		t.Errorf("Expected b then a, got %+v", details.Packages)
+	}
+}
@@ -0,0 +1,144 @@
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/drxc00/sweepy/internal/config"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string // Contents of sweepy.config.json, empty for no file
+		typeYes     int64
+		expectedErr bool
+	}{
+		{name: "No file", typeYes: 10 * 1024 * 1024 * 1024},
+		{name: "Threshold", file: `{"confirmTypeYesAbove": "500MB"}`, typeYes: 500 * 1024 * 1024},
+		{name: "Disabled", file: `{"confirmTypeYesAbove": "0"}`, typeYes: 0},
+		{name: "Invalid size", file: `{"confirmTypeYesAbove": "lots"}`, expectedErr: true},
+		{name: "Invalid JSON", file: `{`, expectedErr: true},
+		{name: "Unknown sort column", file: `{"sort": {"column": "color"}}`, expectedErr: true},
+		{name: "Remapped keys", file: `{"keys": {"up": ["k", "up"], "down": ["j", "down"]}}`, typeYes: 10 * 1024 * 1024 * 1024},
+		{name: "Unknown key action", file: `{"keys": {"jump": ["g"]}}`, expectedErr: true},
+		{name: "No keys", file: `{"keys": {"quit": []}}`, expectedErr: true},
+		{name: "Key bound twice", file: `{"keys": {"up": ["k"], "down": ["k"]}}`, expectedErr: true},
+		{name: "Key of a default action", file: `{"keys": {"select": ["d"]}}`, expectedErr: true},
+		{name: "Space of a default action", file: `{"keys": {"tree": [" "]}}`, expectedErr: true},
+		{name: "Default key moved", file: `{"keys": {"select": ["d"], "delete": ["x"]}}`, typeYes: 10 * 1024 * 1024 * 1024},
+		{name: "Theme and colors", file: `{"theme": "light", "colors": {"primary": "#ff79c6", "ancient": "196"}}`, typeYes: 10 * 1024 * 1024 * 1024},
+		{name: "Unknown theme", file: `{"theme": "solarized"}`, expectedErr: true},
+		{name: "Unknown color", file: `{"colors": {"background": "0"}}`, expectedErr: true},
+		{name: "Invalid color", file: `{"colors": {"primary": "pink"}}`, expectedErr: true},
+		{name: "Color out of range", file: `{"colors": {"primary": "256"}}`, expectedErr: true},
+		{name: "Staleness thresholds", file: `{"stalenessThresholds": {"stale": 30, "old": 60, "ancient": 120}}`, typeYes: 10 * 1024 * 1024 * 1024},
+		{name: "Unordered staleness thresholds", file: `{"stalenessThresholds": {"stale": 200}}`, expectedErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			if test.file != "" {
+				if err := os.WriteFile(config.ConfigFile, []byte(test.file), 0644); err != nil {
+					t.Fatalf("Failed to write config: %v", err)
+				}
+			}
+
+			cfg, err := config.Load()
+			if test.expectedErr {
+				if err == nil {
+					t.Errorf("Expected error, but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.TypeYesAbove() != test.typeYes {
+				t.Errorf("Expected %d, but got %d", test.typeYes, cfg.TypeYesAbove())
+			}
+			if test.typeYes > 0 && (cfg.NeedsTypedYes(test.typeYes) || !cfg.NeedsTypedYes(test.typeYes+1)) {
+				t.Errorf("Expected typed yes only above %d", test.typeYes)
+			}
+		})
+	}
+}
+
+func TestSaveSort(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string // Contents of sweepy.config.json, empty for no file
+		expected    map[string]any
+		expectedErr bool
+	}{
+		{
+			name:     "No file",
+			expected: map[string]any{"sort": map[string]any{"column": "staleness", "descending": false}},
+		},
+		{
+			name: "Other settings kept",
+			file: `{"theme": "light", "keys": {"up": ["k"]}, "sort": {"column": "size", "descending": true}}`,
+			expected: map[string]any{
+				"theme": "light",
+				"keys":  map[string]any{"up": []any{"k"}},
+				"sort":  map[string]any{"column": "staleness", "descending": false},
+			},
+		},
+		{name: "Invalid JSON left alone", file: `{`, expectedErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			if test.file != "" {
+				if err := os.WriteFile(config.ConfigFile, []byte(test.file), 0644); err != nil {
+					t.Fatalf("Failed to write config: %v", err)
+				}
+			}
+
+			err := config.SaveSort(config.Sort{Column: config.SortStaleness, Descending: false})
+			if test.expectedErr {
+				if err == nil {
+					t.Errorf("Expected error, but got nil")
+				}
+				if b, _ := os.ReadFile(config.ConfigFile); string(b) != test.file {
+					t.Errorf("Expected the file to be left as it was, got %q", b)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SaveSort failed: %v", err)
+			}
+
+			// Only the sort key is added, no default is written
+			b, err := os.ReadFile(config.ConfigFile)
+			if err != nil {
+				t.Fatalf("Failed to read config: %v", err)
+			}
+			var saved map[string]any
+			if err := json.Unmarshal(b, &saved); err != nil {
+				t.Fatalf("Saved config is not JSON: %v", err)
+			}
+			if !reflect.DeepEqual(saved, test.expected) {
+				t.Errorf("Expected %v, but got %v", test.expected, saved)
+			}
+
+			loaded, err := config.Load()
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if loaded.Sort != (config.Sort{Column: config.SortStaleness}) {
+				t.Errorf("Expected the saved sort to load, got %+v", loaded.Sort)
+			}
+
+			// Nothing but the config is left in the directory
+			entries, _ := os.ReadDir(".")
+			if len(entries) != 1 {
+				t.Errorf("Expected only %s, got %d files", config.ConfigFile, len(entries))
+			}
+		})
+	}
+}
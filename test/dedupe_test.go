@@ -0,0 +1,68 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drxc00/sweepy/internal/clean"
+)
+
+func TestDedupe(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a/node_modules/lodash/index.js": "module.exports = 'lodash'",
+		"b/node_modules/lodash/index.js": "module.exports = 'lodash'",
+		"c/node_modules/lodash/index.js": "module.exports = 'lodash'",
+		"b/node_modules/other/index.js":  "module.exports = 'LODASH'", // Same size, other content
+		"a/node_modules/empty/index.js":  "",
+		"b/node_modules/empty/index.js":  "",
+	})
+
+	// c is already linked to a, it must not be counted as a duplicate again
+	if err := os.Remove(filepath.Join(dir, "c/node_modules/lodash/index.js")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	if err := os.Link(filepath.Join(dir, "a/node_modules/lodash/index.js"), filepath.Join(dir, "c/node_modules/lodash/index.js")); err != nil {
+		t.Skipf("Hard links are not supported here: %v", err)
+	}
+
+	dirs := []string{
+		filepath.Join(dir, "a/node_modules"),
+		filepath.Join(dir, "b/node_modules"),
+		filepath.Join(dir, "c/node_modules"),
+	}
+	sets, err := clean.FindIdenticalFiles(dirs)
+	if err != nil {
+		t.Fatalf("FindIdenticalFiles failed: %v", err)
+	}
+	if len(sets) != 1 || len(sets[0].Paths) != 2 {
+		t.Fatalf("Expected one set of 2 files, got %+v", sets)
+	}
+
+	saved, err := clean.LinkIdenticalFiles(sets[0])
+	if err != nil {
+		t.Fatalf("LinkIdenticalFiles failed: %v", err)
+	}
+	if saved != sets[0].Size {
+		t.Errorf("Expected %d bytes saved, got %d", sets[0].Size, saved)
+	}
+
+	a, errA := os.Stat(filepath.Join(dir, "a/node_modules/lodash/index.js"))
+	b, errB := os.Stat(filepath.Join(dir, "b/node_modules/lodash/index.js"))
+	if errA != nil || errB != nil {
+		t.Fatalf("Linked files are missing: %v, %v", errA, errB)
+	}
+	if !os.SameFile(a, b) {
+		t.Errorf("Expected the files to be hard linked")
+	}
+
+	// Nothing is left to deduplicate
+	sets, err = clean.FindIdenticalFiles(dirs)
+	if err != nil {
+		t.Fatalf("FindIdenticalFiles failed: %v", err)
+	}
+	if len(sets) != 0 {
+		t.Errorf("Expected no identical files after linking, got %+v", sets)
+	}
+}
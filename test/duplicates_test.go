@@ -0,0 +1,61 @@
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/drxc00/sweepy/internal/scan"
+	"github.com/drxc00/sweepy/types"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a/node_modules/lodash/package.json":    `{"name": "lodash", "version": "4.17.21"}`,
+		"a/node_modules/lodash/index.js":        "module.exports = {}",
+		"a/node_modules/@org/ui/package.json":   `{"name": "@org/ui", "version": "1.0.0"}`,
+		"b/node_modules/lodash/package.json":    `{"name": "lodash", "version": "4.17.21"}`,
+		"b/node_modules/lodash/index.js":        "module.exports = {}",
+		"b/node_modules/@org/ui/package.json":   `{"name": "@org/ui", "version": "2.0.0"}`,
+		"c/node_modules/lodash/package.json":    `{"name": "lodash", "version": "4.17.21"}`,
+		"c/node_modules/lodash/index.js":        "module.exports = {}",
+		"c/node_modules/.cache/data":            "cache",
+		"pnpm/node_modules/lodash/package.json": `{"name": "lodash", "version": "4.17.21"}`,
+		"pnpm/node_modules/lodash/index.js":     "module.exports = {}",
+	})
+
+	var modules []types.ScannedNodeModule
+	for _, project := range []string{"a", "b", "c", "pnpm"} {
+		module := types.ScannedNodeModule{Path: filepath.Join(dir, project, "node_modules")}
+		if project == "pnpm" {
+			module.Project.PackageManager = "pnpm"
+		}
+		modules = append(modules, module)
+	}
+
+	duplicates := scan.FindDuplicates(modules)
+	if len(duplicates) != 1 {
+		t.Fatalf("Expected only lodash to be duplicated, got %+v", duplicates)
+	}
+
+	lodash := duplicates[0]
+	if lodash.Name != "lodash" || lodash.Version != "4.17.21" {
+		t.Errorf("Expected lodash@4.17.21, got %s@%s", lodash.Name, lodash.Version)
+	}
+	if len(lodash.Projects) != 4 {
+		t.Errorf("Expected 4 copies, got %d", len(lodash.Projects))
+	}
+	if lodash.Wasted != lodash.Size*3 {
+		t.Errorf("Expected %d wasted bytes, got %d", lodash.Size*3, lodash.Wasted)
+	}
+
+	candidates := scan.SharedStoreCandidates(modules, duplicates)
+	if len(candidates) != 3 {
+		t.Fatalf("Expected the 3 projects not using pnpm, got %+v", candidates)
+	}
+	for _, c := range candidates {
+		if c.Duplicated != 1 || c.Size != lodash.Size {
+			t.Errorf("Expected %s to have 1 duplicated package of %d bytes, got %d of %d", c.Path, lodash.Size, c.Duplicated, c.Size)
+		}
+	}
+}
@@ -0,0 +1,429 @@
+package test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drxc00/sweepy/internal/clean"
+	"github.com/drxc00/sweepy/internal/git"
+	"github.com/drxc00/sweepy/internal/scan"
+	"github.com/drxc00/sweepy/types"
+)
+
+// runGit runs the git binary to build test fixtures, sweepy itself never needs it.
+func runGit(t *testing.T, dir string, date time.Time, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	stamp := date.Format(time.RFC3339)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=sweepy", "GIT_AUTHOR_EMAIL=sweepy@example.com", "GIT_AUTHOR_DATE="+stamp,
+		"GIT_COMMITTER_NAME=sweepy", "GIT_COMMITTER_EMAIL=sweepy@example.com", "GIT_COMMITTER_DATE="+stamp,
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_SYSTEM=/dev/null",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func setupGitRepo(t *testing.T, commitDate time.Time) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available to build fixtures")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, commitDate, "init", "-q", "-b", "main")
+
+	// Several versions of the same file so packing produces deltas
+	content := strings.Repeat("console.log('hello world');\n", 200)
+	for i := range 5 {
+		content += "// change " + string(rune('a'+i)) + "\n"
+		if err := os.WriteFile(filepath.Join(dir, "index.js"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		runGit(t, dir, commitDate, "add", "index.js")
+		runGit(t, dir, commitDate, "commit", "-q", "-m", "commit")
+	}
+	return dir
+}
+
+func TestGitLastCommit(t *testing.T) {
+	commitDate := time.Date(2020, 5, 17, 12, 0, 0, 0, time.UTC)
+	repoDir := setupGitRepo(t, commitDate)
+
+	// A project in a sub directory of the repository
+	projectDir := filepath.Join(repoDir, "packages", "app")
+	if err := os.MkdirAll(filepath.Join(projectDir, "node_modules"), 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, "package.json"), []byte("{}"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	check := func(t *testing.T, expectedRef string) {
+		lastCommit, ref, err := git.LastCommit(projectDir)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !lastCommit.Equal(commitDate) {
+			t.Errorf("Expected %v, got %v", commitDate, lastCommit)
+		}
+		if ref != expectedRef {
+			t.Errorf("Expected ref %q, got %q", expectedRef, ref)
+		}
+	}
+
+	t.Run("Loose objects", func(t *testing.T) {
+		check(t, "main")
+	})
+
+	t.Run("Packed objects and refs", func(t *testing.T) {
+		runGit(t, repoDir, commitDate, "gc", "-q", "--aggressive")
+		if _, err := os.Stat(filepath.Join(repoDir, ".git", "refs", "heads", "main")); err == nil {
+			t.Fatal("Expected refs to be packed")
+		}
+		check(t, "main")
+
+		// Older versions of index.js are stored as deltas, reading them exercises delta resolution
+		for _, rev := range []string{"HEAD~1", "HEAD~4"} {
+			hash := runGit(t, repoDir, commitDate, "rev-parse", rev+":index.js")
+			repo, err := git.Open(repoDir)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			typ, data, err := repo.ReadObject(hash)
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", rev, err)
+			}
+			expected := runGit(t, repoDir, commitDate, "cat-file", "-p", hash)
+			if typ != "blob" || strings.TrimSpace(string(data)) != expected {
+				t.Errorf("Unexpected content for %s", rev)
+			}
+		}
+	})
+
+	t.Run("Detached HEAD", func(t *testing.T) {
+		hash := runGit(t, repoDir, commitDate, "rev-parse", "HEAD")
+		runGit(t, repoDir, commitDate, "checkout", "-q", "--detach")
+		check(t, hash[:7])
+	})
+
+	t.Run("Staleness from git", func(t *testing.T) {
+		lastActivity, _, err := scan.LastActivity(projectDir, types.StalenessSourceGit)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !lastActivity.Equal(commitDate) {
+			t.Errorf("Expected %v, got %v", commitDate, lastActivity)
+		}
+
+		// The checked out files are newer than the commit
+		lastActivity, _, err = scan.LastActivity(projectDir, types.StalenessSourceMax)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !lastActivity.After(commitDate) {
+			t.Errorf("Expected max to pick the newer mtime, got %v", lastActivity)
+		}
+	})
+}
+
+func TestGitNotRepo(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := git.Open(dir); err == nil {
+		// The temp dir could live inside a repository on some machines
+		t.Skip("Temp directory is inside a git repository")
+	}
+
+	if _, _, err := git.LastCommit(dir); err == nil {
+		t.Error("Expected an error outside of a git repository")
+	}
+}
+
+func TestGitStatus(t *testing.T) {
+	commitDate := time.Now().Add(-time.Hour)
+	repoDir := setupGitRepo(t, commitDate)
+	indexFile := filepath.Join(repoDir, "index.js")
+
+	expectStatus := func(t *testing.T, expected git.WorkStatus) {
+		t.Helper()
+		status, err := git.Status(repoDir)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if status != expected {
+			t.Errorf("Expected %+v, got %+v", expected, status)
+		}
+	}
+
+	t.Run("Clean repository without remote", func(t *testing.T) {
+		expectStatus(t, git.WorkStatus{})
+	})
+
+	t.Run("Unstaged change", func(t *testing.T) {
+		original, err := os.ReadFile(indexFile)
+		if err != nil {
+			t.Fatalf("Failed to read file: %v", err)
+		}
+		if err := os.WriteFile(indexFile, []byte("changed"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		expectStatus(t, git.WorkStatus{Dirty: true})
+
+		if err := os.WriteFile(indexFile, original, 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		// Same content, different mtime: the content hash decides
+		expectStatus(t, git.WorkStatus{})
+	})
+
+	t.Run("Staged new file with index v4", func(t *testing.T) {
+		runGit(t, repoDir, commitDate, "update-index", "--index-version", "4")
+		if err := os.WriteFile(filepath.Join(repoDir, "new.js"), []byte("new"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		runGit(t, repoDir, commitDate, "add", "new.js")
+		expectStatus(t, git.WorkStatus{Dirty: true})
+
+		runGit(t, repoDir, commitDate, "commit", "-q", "-m", "new file")
+		expectStatus(t, git.WorkStatus{})
+	})
+
+	t.Run("Unpushed commits", func(t *testing.T) {
+		remoteDir := t.TempDir()
+		runGit(t, remoteDir, commitDate, "init", "-q", "--bare")
+		runGit(t, repoDir, commitDate, "remote", "add", "origin", remoteDir)
+
+		// A remote exists but the branch was never pushed
+		expectStatus(t, git.WorkStatus{Unpushed: true})
+
+		runGit(t, repoDir, commitDate, "push", "-q", "-u", "origin", "main")
+		expectStatus(t, git.WorkStatus{})
+
+		runGit(t, repoDir, commitDate, "commit", "-q", "--allow-empty", "-m", "local only")
+		expectStatus(t, git.WorkStatus{Unpushed: true})
+
+		// Behind the upstream is fine, nothing would be lost
+		runGit(t, repoDir, commitDate, "push", "-q")
+		runGit(t, repoDir, commitDate, "reset", "-q", "--hard", "HEAD~1")
+		expectStatus(t, git.WorkStatus{})
+	})
+}
+
+func TestGitBranchCleanup(t *testing.T) {
+	now := time.Now()
+	old := now.AddDate(0, 0, -200)
+	repoDir := setupGitRepo(t, old)
+
+	// merged: fully merged into main
+	// stale: old commit not on main
+	// active: recent commit not on main
+	runGit(t, repoDir, old, "branch", "merged")
+	runGit(t, repoDir, old, "checkout", "-q", "-b", "stale")
+	runGit(t, repoDir, old, "commit", "-q", "--allow-empty", "-m", "stale work")
+	runGit(t, repoDir, now, "checkout", "-q", "-b", "active", "main")
+	runGit(t, repoDir, now, "commit", "-q", "--allow-empty", "-m", "active work")
+	runGit(t, repoDir, now, "checkout", "-q", "main")
+	runGit(t, repoDir, now, "pack-refs", "--all")
+
+	ch := make(chan string, 100)
+	ctx := types.ScanContext{Path: filepath.Dir(repoDir), Staleness: 90}
+	branches, err := scan.GitScan(ctx, ch)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	found := map[string]types.StaleBranch{}
+	for _, b := range branches {
+		if b.Repo == repoDir {
+			found[b.Name] = b
+		}
+	}
+	if len(found) != 2 || !found["merged"].Merged || found["stale"].Merged || found["stale"].Staleness < 90 {
+		t.Fatalf("Expected merged and stale branches, got %+v", found)
+	}
+
+	roots := ctx.Roots()
+	for _, b := range found {
+		if err := clean.DeleteBranch(b, roots); err != nil {
+			t.Fatalf("Failed to delete %s: %v", b.Name, err)
+		}
+	}
+
+	repo, err := git.Open(repoDir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	remaining, err := repo.Branches()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(remaining) != 2 {
+		t.Errorf("Expected main and active to remain, got %v", remaining)
+	}
+	if out := runGit(t, repoDir, now, "branch", "--list", "merged", "stale"); out != "" {
+		t.Errorf("Expected git to agree the branches are gone, got %q", out)
+	}
+
+	// The unmerged commit of stale must survive a gc, the undo ref keeps it reachable
+	runGit(t, repoDir, now, "reflog", "expire", "--expire=now", "--all")
+	runGit(t, repoDir, now, "gc", "-q", "--prune=now")
+
+	restored, err := clean.RestoreBranches(repoDir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(restored) != 2 {
+		t.Errorf("Expected 2 restored branches, got %v", restored)
+	}
+	if hash := runGit(t, repoDir, now, "rev-parse", "stale"); hash != found["stale"].Hash {
+		t.Errorf("Expected stale to point to %s, got %s", found["stale"].Hash, hash)
+	}
+	if typ := runGit(t, repoDir, now, "cat-file", "-t", found["stale"].Hash); typ != "commit" {
+		t.Errorf("Expected the commit of stale to survive gc, got %q", typ)
+	}
+	if out := runGit(t, repoDir, now, "for-each-ref", "refs/sweepy-undo/"); out != "" {
+		t.Errorf("Expected the undo refs to go once restored, got %q", out)
+	}
+
+	// Restoring twice is a no-op
+	restored, err = clean.RestoreBranches(repoDir)
+	if err != nil || len(restored) != 0 {
+		t.Errorf("Expected nothing to restore, got %v (%v)", restored, err)
+	}
+}
+
+func TestGitBranchCleanupWorktrees(t *testing.T) {
+	now := time.Now()
+	repoDir := setupGitRepo(t, now)
+
+	// Both merged into main, feature is checked out in a linked worktree.
+	// The temp dirs of a test share a parent, the scan root holds the repository and the worktree.
+	runGit(t, repoDir, now, "branch", "feature")
+	runGit(t, repoDir, now, "branch", "merged")
+	worktree := filepath.Join(t.TempDir(), "feature")
+	runGit(t, repoDir, now, "worktree", "add", "-q", worktree, "feature")
+
+	ch := make(chan string, 100)
+	branches, err := scan.GitScan(types.ScanContext{Path: filepath.Dir(repoDir)}, ch)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(branches) != 1 || branches[0].Name != "merged" || branches[0].Repo != repoDir {
+		t.Fatalf("Expected merged once, in the main work tree, got %+v", branches)
+	}
+
+	repo, err := git.Open(repoDir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := repo.DeleteBranch("feature"); !errors.Is(err, git.ErrCurrentBranch) {
+		t.Errorf("Expected ErrCurrentBranch for the worktree's branch, got %v", err)
+	}
+}
+
+func TestUnsavedWork(t *testing.T) {
+	date := time.Date(2020, 5, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		setup        func(t *testing.T) string // Returns the project directory
+		expected     string
+		expectPrefix bool
+	}{
+		{
+			name:     "Clean repository",
+			setup:    func(t *testing.T) string { return setupGitRepo(t, date) },
+			expected: "",
+		},
+		{
+			name: "Uncommitted changes",
+			setup: func(t *testing.T) string {
+				dir := setupGitRepo(t, date)
+				if err := os.WriteFile(filepath.Join(dir, "index.js"), []byte("changed"), 0644); err != nil {
+					t.Fatalf("Failed to write file: %v", err)
+				}
+				return dir
+			},
+			expected: "uncommitted changes",
+		},
+		{
+			name:     "Not a repository",
+			setup:    func(t *testing.T) string { return t.TempDir() },
+			expected: "",
+		},
+		{
+			name: "Corrupt index",
+			setup: func(t *testing.T) string {
+				dir := setupGitRepo(t, date)
+				if err := os.WriteFile(filepath.Join(dir, ".git", "index"), []byte("not an index"), 0644); err != nil {
+					t.Fatalf("Failed to corrupt the index: %v", err)
+				}
+				return dir
+			},
+			expected:     "git status unknown",
+			expectPrefix: true,
+		},
+		{
+			name: "Missing objects",
+			setup: func(t *testing.T) string {
+				dir := setupGitRepo(t, date)
+				objects := filepath.Join(dir, ".git", "objects")
+				entries, err := os.ReadDir(objects)
+				if err != nil {
+					t.Fatalf("Failed to read objects: %v", err)
+				}
+				for _, entry := range entries {
+					if len(entry.Name()) == 2 {
+						os.RemoveAll(filepath.Join(objects, entry.Name()))
+					}
+				}
+				return dir
+			},
+			expected:     "git status unknown",
+			expectPrefix: true,
+		},
+		{
+			name: "Unsupported object format",
+			setup: func(t *testing.T) string {
+				dir := t.TempDir()
+				if err := exec.Command("git", "-C", dir, "init", "-q", "--object-format=sha256").Run(); err != nil {
+					t.Skipf("git cannot create sha256 repositories: %v", err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, "index.js"), []byte("sha256"), 0644); err != nil {
+					t.Fatalf("Failed to write file: %v", err)
+				}
+				runGit(t, dir, date, "add", "index.js")
+				runGit(t, dir, date, "commit", "-q", "-m", "commit")
+				return dir
+			},
+			expected:     "git status unknown",
+			expectPrefix: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := clean.UnsavedWork(test.setup(t))
+			if test.expectPrefix {
+				if !strings.HasPrefix(got, test.expected) {
+					t.Errorf("Expected %q..., but got %q", test.expected, got)
+				}
+				return
+			}
+			if got != test.expected {
+				t.Errorf("Expected %q, but got %q", test.expected, got)
+			}
+		})
+	}
+}
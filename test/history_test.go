@@ -0,0 +1,151 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/drxc00/sweepy/internal/clean"
+	"github.com/drxc00/sweepy/types"
+)
+
+// tempJournal keeps the journal of a test out of the user config directory.
+func tempJournal(t *testing.T) {
+	t.Helper()
+	t.Setenv(clean.JournalEnv, filepath.Join(t.TempDir(), "journal.jsonl"))
+}
+
+// The journal is kept once per user, a removal made in one directory is found from any other.
+func TestJournalSharedAcrossDirectories(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(clean.JournalEnv, "")
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("AppData", filepath.Join(home, "AppData"))
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Skipf("No user config directory: %v", err)
+	}
+	if expected := filepath.Join(configDir, "sweepy", "journal.jsonl"); clean.JournalPath() != expected {
+		t.Fatalf("Expected the journal at %s, but got %s", expected, clean.JournalPath())
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"app/package.json": "{}", "app/node_modules/a/index.js": ""})
+	nm := filepath.Join(dir, "app", "node_modules")
+
+	t.Chdir(t.TempDir())
+	if err := clean.CleanNodeModule(nm, []string{dir}); err != nil {
+		t.Fatalf("CleanNodeModule failed: %v", err)
+	}
+
+	t.Chdir(t.TempDir())
+	if _, found, err := clean.LastRemoval(nm); err != nil || !found {
+		t.Errorf("Expected the removal to be found from another directory, got %v, %v", found, err)
+	}
+}
+
+func TestJournalRecordsAttempts(t *testing.T) {
+	t.Chdir(t.TempDir())
+	tempJournal(t)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"app/node_modules/a/index.js": "0123456789",
+		"app/src/index.js":            "",
+	})
+
+	if err := clean.CleanNodeModule(filepath.Join(dir, "app", "node_modules"), []string{dir}); err != nil {
+		t.Fatalf("CleanNodeModule failed: %v", err)
+	}
+	// Refused, not an artifact directory
+	if err := clean.CleanNodeModule(filepath.Join(dir, "app", "src"), []string{dir}); err == nil {
+		t.Fatalf("Expected src to be refused")
+	}
+
+	entries, err := clean.ReadJournal()
+	if err != nil {
+		t.Fatalf("ReadJournal failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 journal entries, got %d", len(entries))
+	}
+
+	ok, failed := entries[0], entries[1]
+	if ok.Result != types.JournalResultOK || ok.Mode != types.JournalModeRemove || ok.Bytes != 10 {
+		t.Errorf("Expected a 10 bytes ok rm entry, got %+v", ok)
+	}
+	if ok.User == "" {
+		t.Errorf("Expected the user to be recorded")
+	}
+	if failed.Result != types.JournalResultFailed || failed.Error == "" {
+		t.Errorf("Expected a failed entry with its error, got %+v", failed)
+	}
+}
+
+func TestJournalTotals(t *testing.T) {
+	day := func(s string) time.Time {
+		d, _ := time.ParseInLocation("2006-01-02", s, time.Local)
+		return d
+	}
+	entries := []types.JournalEntry{
+		{Time: day("2025-01-10"), Path: "/a/node_modules", Bytes: 100, Mode: "rm", Result: "ok", User: "ana"},
+		{Time: day("2025-02-10"), Path: "/b/node_modules", Bytes: 200, Mode: "archive", Result: "ok", User: "ben"},
+		{Time: day("2025-02-11"), Path: "/c/node_modules", Bytes: 400, Mode: "rm", Result: "failed", User: "ana"},
+		{Time: day("2025-05-01"), Path: "/a/node_modules", Bytes: 800, Mode: "rm", Result: "ok", User: "ana"},
+	}
+
+	tests := []struct {
+		name     string
+		filter   clean.JournalFilter
+		by       string
+		expected []clean.JournalTotal
+	}{
+		{
+			name:     "All",
+			expected: []clean.JournalTotal{{Period: "all", Attempts: 4, Failed: 1, Reclaimed: 1100}},
+		},
+		{
+			name: "By quarter",
+			by:   "quarter",
+			expected: []clean.JournalTotal{
+				{Period: "2025-Q1", Attempts: 3, Failed: 1, Reclaimed: 300},
+				{Period: "2025-Q2", Attempts: 1, Failed: 0, Reclaimed: 800},
+			},
+		},
+		{
+			name:     "Filtered by user and date",
+			filter:   clean.JournalFilter{User: "ana", Since: day("2025-02-01"), Until: day("2025-03-01")},
+			by:       "month",
+			expected: []clean.JournalTotal{{Period: "2025-02", Attempts: 1, Failed: 1, Reclaimed: 0}},
+		},
+		{
+			name:     "Filtered by path and mode",
+			filter:   clean.JournalFilter{Path: "/a/", Mode: "rm"},
+			expected: []clean.JournalTotal{{Period: "all", Attempts: 2, Failed: 0, Reclaimed: 900}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var matched []types.JournalEntry
+			for _, entry := range entries {
+				if tt.filter.Match(entry) {
+					matched = append(matched, entry)
+				}
+			}
+
+			totals := clean.TotalsBy(matched, tt.by)
+			if len(totals) != len(tt.expected) {
+				t.Fatalf("Expected %+v, got %+v", tt.expected, totals)
+			}
+			for i := range totals {
+				if totals[i] != tt.expected[i] {
+					t.Errorf("Expected %+v, got %+v", tt.expected[i], totals[i])
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package test
 
 import (
+	"errors"
+	"os"
 	"path/filepath"
 	"testing"
 	"time"
@@ -14,6 +16,8 @@ import (
 )
 
 func TestNodeClean(t *testing.T) {
+	t.Chdir(t.TempDir())
+	tempJournal(t)
 
 	tests := []struct {
 		name          string
@@ -93,7 +97,7 @@ func TestNodeClean(t *testing.T) {
 
 			// Clean the modules
 			for _, ap := range absolutePaths {
-				c_err := clean.CleanNodeModule(ap)
+				c_err := clean.CleanNodeModule(ap, t_ctx.Roots())
 				if c_err != nil && !tt.expectError {
 					t.Fatal("Did not expect error but got one")
 				} else if c_err == nil && tt.expectError {
@@ -130,3 +134,86 @@ func TestNodeClean(t *testing.T) {
 		})
 	}
 }
+
+func TestNodeCleanSafety(t *testing.T) {
+	t.Chdir(t.TempDir())
+	tempJournal(t)
+	testDir, projectPaths, cleanup := utils.SetupTestDirectory(t)
+	defer cleanup()
+
+	// A directory outside the scan root
+	outsideDir, err := os.MkdirTemp("", "sweepy-outside-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(outsideDir)
+	outsideModules := filepath.Join(outsideDir, "node_modules")
+	if err := os.MkdirAll(outsideModules, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+
+	// A node_modules that is a symlink to a directory outside the root
+	linkedModules := filepath.Join(testDir, "linked", "node_modules")
+	if err := os.MkdirAll(filepath.Dir(linkedModules), 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	if err := os.Symlink(outsideModules, linkedModules); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	roots := []string{testDir}
+
+	tests := []struct {
+		name        string
+		path        string
+		expectedErr error
+	}{
+		{
+			name:        "Refuse non artifact directory",
+			path:        filepath.Dir(projectPaths[0]),
+			expectedErr: clean.ErrNotArtifact,
+		},
+		{
+			name:        "Refuse path outside roots",
+			path:        outsideModules,
+			expectedErr: clean.ErrOutsideRoots,
+		},
+		{
+			name:        "Refuse scan root itself",
+			path:        testDir,
+			expectedErr: clean.ErrNotArtifact,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := clean.CleanNodeModule(tt.path, roots)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Expected %v, got %v", tt.expectedErr, err)
+			}
+
+			var unsafeErr *clean.UnsafePathError
+			if !errors.As(err, &unsafeErr) {
+				t.Errorf("Expected an UnsafePathError, got %T", err)
+			}
+
+			if _, err := os.Stat(tt.path); err != nil {
+				t.Errorf("Expected %s to still exist", tt.path)
+			}
+		})
+	}
+
+	t.Run("Unlink symlinked module", func(t *testing.T) {
+		if err := clean.CleanNodeModule(linkedModules, roots); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if _, err := os.Lstat(linkedModules); !os.IsNotExist(err) {
+			t.Error("Expected the symlink to be removed")
+		}
+
+		if _, err := os.Stat(outsideModules); err != nil {
+			t.Error("Expected the symlink target to be left alone")
+		}
+	})
+}
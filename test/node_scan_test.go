@@ -119,3 +119,145 @@ func TestGetLastModified(t *testing.T) {
 		t.Error("Last modified time is older than expected")
 	}
 }
+
+func TestNodeScanSymlinks(t *testing.T) {
+	testDir, projectPaths, cleanup := utils.SetupTestDirectory(t)
+	defer cleanup()
+
+	// A project living outside the scan root
+	outsideDir, err := os.MkdirTemp("", "sweepy-outside-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(outsideDir)
+	outsideModules := filepath.Join(outsideDir, "linked-project", "node_modules")
+	if err := os.MkdirAll(outsideModules, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	bigFile := filepath.Join(outsideModules, "big.js")
+	if err := os.WriteFile(bigFile, make([]byte, 4096), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	links := map[string]string{
+		// Symlinked node_modules, like `npm link` or workspaces produce
+		filepath.Join(testDir, "project1", "node_modules", "big.js"): bigFile,
+		filepath.Join(testDir, "app", "node_modules"):                outsideModules,
+		// Symlinked project directory and a loop back to the root
+		filepath.Join(testDir, "external"): outsideDir,
+		filepath.Join(testDir, "loop"):     testDir,
+	}
+	for link, target := range links {
+		if err := os.MkdirAll(filepath.Dir(link), 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+		if err := os.Symlink(target, link); err != nil {
+			t.Skipf("Symlinks not supported: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		followSymlinks bool
+		expectedCount  int
+	}{
+		{
+			name:           "Do not follow symlinks",
+			followSymlinks: false,
+			expectedCount:  4,
+		},
+		{
+			name:           "Follow symlinks",
+			followSymlinks: true,
+			expectedCount:  5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := make(chan string)
+			go func() {
+				for range ch {
+				}
+			}()
+
+			ctx := types.ScanContext{
+				Path:           testDir,
+				NoCache:        true,
+				FollowSymlinks: tt.followSymlinks,
+			}
+			modules, _, err := scan.NodeScan(ctx, ch)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(modules) != tt.expectedCount {
+				t.Fatalf("Expected %d modules, got %d", tt.expectedCount, len(modules))
+			}
+
+			for _, module := range modules {
+				switch module.Path {
+				case filepath.Join(testDir, "app", "node_modules"):
+					if !module.IsSymlink {
+						t.Error("Expected symlinked node_modules to be reported as a symlink")
+					}
+					if module.Size != 0 {
+						t.Errorf("Expected symlinked node_modules to have no size, got %d", module.Size)
+					}
+				case projectPaths[0]:
+					if module.Size >= 4096 {
+						t.Errorf("Expected bytes behind symlinks to be ignored, got %d", module.Size)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestNodeScanCacheStalenessSource(t *testing.T) {
+	testDir, _, cleanup := utils.SetupTestDirectory(t)
+	defer cleanup()
+	t.Chdir(t.TempDir()) // The cache file is written to the current directory
+
+	scanWith := func(source string) []types.ScannedNodeModule {
+		ch := make(chan string)
+		go func() {
+			for range ch {
+				// Consume progress messages
+			}
+		}()
+		modules, _, err := scan.NodeScan(types.ScanContext{Path: testDir, StalenessSource: source}, ch)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return modules
+	}
+
+	tests := []struct {
+		name      string
+		source    string
+		fromCache bool
+	}{
+		{name: "First scan", source: types.StalenessSourceMtime, fromCache: false},
+		{name: "Same source", source: types.StalenessSourceMtime, fromCache: true},
+		{name: "Other source", source: types.StalenessSourceGit, fromCache: false},
+		{name: "Other source cached", source: types.StalenessSourceGit, fromCache: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modules := scanWith(tt.source)
+			if len(modules) == 0 {
+				t.Fatal("Expected modules, got none")
+			}
+			for _, module := range modules {
+				if module.FromCache != tt.fromCache {
+					t.Errorf("Expected %s from cache %v, got %v", module.Path, tt.fromCache, module.FromCache)
+				}
+				if module.StalenessSource != tt.source {
+					t.Errorf("Expected %s staleness source %s, got %s", module.Path, tt.source, module.StalenessSource)
+				}
+			}
+		})
+	}
+}
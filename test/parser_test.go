@@ -47,3 +47,38 @@ func TestParseStalenessFlagValue(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		name        string
+		size        string
+		expected    int64
+		expectedErr bool
+	}{
+		{name: "Bytes", size: "2048", expected: 2048},
+		{name: "Megabytes", size: "500MB", expected: 500 * 1024 * 1024},
+		{name: "Fraction with space", size: "1.5 gb", expected: 1536 * 1024 * 1024},
+		{name: "Unknown unit", size: "3PB", expectedErr: true},
+		{name: "Negative", size: "-1GB", expectedErr: true},
+		{name: "Empty", size: "", expectedErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := utils.ParseSize(test.size)
+
+			if test.expectedErr {
+				if err == nil {
+					t.Errorf("Expected error, but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if actual != test.expected {
+				t.Errorf("Expected %d, but got %d", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/drxc00/sweepy/internal/project"
+	"github.com/drxc00/sweepy/types"
+)
+
+// writeFiles creates files (and their directories) relative to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"npm/package.json":                     "{}",
+		"npm/package-lock.json":                "{}",
+		"pnpm/package.json":                    "{}",
+		"pnpm/pnpm-lock.yaml":                  "",
+		"nolock/package.json":                  "{}",
+		"nopackage/index.js":                   "",
+		"monorepo/package.json":                `{"workspaces": ["packages/*"]}`,
+		"monorepo/yarn.lock":                   "",
+		"monorepo/packages/app/package.json":   "{}",
+		"monorepo/packages/app/node_modules/x": "",
+	})
+
+	tests := []struct {
+		name     string
+		dir      string
+		expected types.Reproducibility
+	}{
+		{name: "npm lockfile", dir: "npm", expected: types.ReproducibilityReproducible},
+		{name: "pnpm lockfile", dir: "pnpm", expected: types.ReproducibilityReproducible},
+		{name: "No lockfile", dir: "nolock", expected: types.ReproducibilityRisky},
+		{name: "No package.json", dir: "nopackage", expected: types.ReproducibilityUnknown},
+		{name: "Workspace member uses the root lockfile", dir: "monorepo/packages/app", expected: types.ReproducibilityReproducible},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := project.Classify(filepath.Join(dir, tt.dir))
+			if actual != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestReadMeta(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"web/package.json": `{
+			"name": "@acme/web",
+			"version": "1.2.0",
+			"packageManager": "pnpm@8.6.0+sha256.abc",
+			"engines": {"node": ">=18"}
+		}`,
+		"yarn/package.json":     `{"name": "tools", "workspaces": {"packages": ["tools/*"]}}`,
+		"yarn/yarn.lock":        "",
+		"npm/package.json":      `{"name": "api", "workspaces": ["packages/*", "apps/*"]}`,
+		"npm/package-lock.json": "{}",
+	})
+
+	tests := []struct {
+		name     string
+		dir      string
+		expected types.ProjectMeta
+	}{
+		{
+			name: "packageManager field",
+			dir:  "web",
+			expected: types.ProjectMeta{
+				Name:           "@acme/web",
+				Version:        "1.2.0",
+				PackageManager: "pnpm",
+				NodeEngine:     ">=18",
+			},
+		},
+		{
+			name: "Manager from lockfile with yarn workspaces",
+			dir:  "yarn",
+			expected: types.ProjectMeta{
+				Name:           "tools",
+				PackageManager: "yarn",
+				Workspaces:     []string{"tools/*"},
+			},
+		},
+		{
+			name: "npm workspaces",
+			dir:  "npm",
+			expected: types.ProjectMeta{
+				Name:           "api",
+				PackageManager: "npm",
+				Workspaces:     []string{"packages/*", "apps/*"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := project.ReadMeta(filepath.Join(dir, tt.dir))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, actual)
+			}
+		})
+	}
+
+	if _, err := project.ReadMeta(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error without a package.json")
+	}
+}
+
+func TestFindWorkspaceRoot(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"yarn/package.json":                 `{"workspaces": {"packages": ["packages/*", "!packages/legacy"]}}`,
+		"yarn/packages/app/package.json":    "{}",
+		"yarn/packages/legacy/package.json": "{}",
+		"pnpm/package.json":                 "{}",
+		"pnpm/pnpm-workspace.yaml":          "packages:\n  - 'apps/**'\n  # comment\n  - \"libs/*\"\ncatalog:\n  - ignored\n",
+		"pnpm/apps/web/site/package.json":   "{}",
+		"pnpm/libs/ui/package.json":         "{}",
+		"pnpm/tools/package.json":           "{}",
+		"standalone/package.json":           "{}",
+	})
+
+	tests := []struct {
+		name     string
+		dir      string
+		expected string
+	}{
+		{name: "Workspace root", dir: "yarn", expected: "yarn"},
+		{name: "Yarn member", dir: "yarn/packages/app", expected: "yarn"},
+		{name: "Excluded member", dir: "yarn/packages/legacy", expected: ""},
+		{name: "pnpm nested member", dir: "pnpm/apps/web/site", expected: "pnpm"},
+		{name: "pnpm member", dir: "pnpm/libs/ui", expected: "pnpm"},
+		{name: "Not listed in pnpm workspace", dir: "pnpm/tools", expected: ""},
+		{name: "Standalone project", dir: "standalone", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := tt.expected
+			if expected != "" {
+				expected = filepath.Join(dir, expected)
+			}
+			actual := project.FindWorkspaceRoot(filepath.Join(dir, tt.dir))
+			if actual != expected {
+				t.Errorf("Expected %q, got %q", expected, actual)
+			}
+		})
+	}
+}
+
+func TestScopeFindWorkspaceRoot(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"mono/package.json":              `{"workspaces": ["packages/*"]}`,
+		"mono/package-lock.json":         "{}",
+		"mono/packages/app/package.json": "{}",
+	})
+	member := filepath.Join(dir, "mono", "packages", "app")
+
+	tests := []struct {
+		name          string
+		roots         []string
+		expected      string
+		expectedClass types.Reproducibility
+	}{
+		{name: "Unbounded", expected: "mono", expectedClass: types.ReproducibilityReproducible},
+		{name: "Workspace root scanned", roots: []string{filepath.Join(dir, "mono")}, expected: "mono", expectedClass: types.ReproducibilityReproducible},
+		{name: "Workspace root above the scan", roots: []string{filepath.Join(dir, "mono", "packages")}, expected: "", expectedClass: types.ReproducibilityRisky},
+		{name: "Scan of the member itself", roots: []string{member}, expected: "", expectedClass: types.ReproducibilityRisky},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := tt.expected
+			if expected != "" {
+				expected = filepath.Join(dir, expected)
+			}
+			scope := project.NewScope(tt.roots)
+			if actual := scope.FindWorkspaceRoot(member); actual != expected {
+				t.Errorf("Expected %q, got %q", expected, actual)
+			}
+			if actual := scope.Classify(member); actual != tt.expectedClass {
+				t.Errorf("Expected %s, got %s", tt.expectedClass, actual)
+			}
+		})
+	}
+}
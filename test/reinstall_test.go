@@ -0,0 +1,91 @@
+package test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/drxc00/sweepy/internal/clean"
+	"github.com/drxc00/sweepy/internal/project"
+)
+
+func TestInstallCommand(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"npm/package.json":               "{}",
+		"npm/package-lock.json":          "{}",
+		"pnpm/package.json":              `{"packageManager": "pnpm@9.1.0"}`,
+		"pnpm/pnpm-lock.yaml":            "",
+		"yarn1/package.json":             "{}",
+		"yarn1/yarn.lock":                "",
+		"berry/package.json":             "{}",
+		"berry/yarn.lock":                "",
+		"berry/.yarnrc.yml":              "nodeLinker: node-modules",
+		"bun/package.json":               "{}",
+		"bun/bun.lockb":                  "",
+		"nolock/package.json":            "{}",
+		"mono/package.json":              `{"workspaces": ["packages/*"]}`,
+		"mono/package-lock.json":         "{}",
+		"mono/packages/app/package.json": "{}",
+	})
+
+	tests := []struct {
+		name       string
+		dir        string
+		expectDir  string
+		expectArgs []string
+	}{
+		{name: "npm", dir: "npm", expectDir: "npm", expectArgs: []string{"npm", "ci"}},
+		{name: "pnpm", dir: "pnpm", expectDir: "pnpm", expectArgs: []string{"pnpm", "install", "--frozen-lockfile"}},
+		{name: "Yarn classic", dir: "yarn1", expectDir: "yarn1", expectArgs: []string{"yarn", "install", "--frozen-lockfile"}},
+		{name: "Yarn berry", dir: "berry", expectDir: "berry", expectArgs: []string{"yarn", "install", "--immutable"}},
+		{name: "bun", dir: "bun", expectDir: "bun", expectArgs: []string{"bun", "install", "--frozen-lockfile"}},
+		{name: "No lockfile", dir: "nolock", expectDir: "nolock", expectArgs: []string{"npm", "install"}},
+		{name: "Workspace member installs from the root", dir: "mono/packages/app", expectDir: "mono", expectArgs: []string{"npm", "ci"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installDir, args := project.InstallCommand(filepath.Join(dir, tt.dir))
+			if installDir != filepath.Join(dir, tt.expectDir) {
+				t.Errorf("Expected to run in %s, got %s", filepath.Join(dir, tt.expectDir), installDir)
+			}
+			if !reflect.DeepEqual(args, tt.expectArgs) {
+				t.Errorf("Expected %v, got %v", tt.expectArgs, args)
+			}
+		})
+	}
+}
+
+func TestJournalLockfileChange(t *testing.T) {
+	t.Chdir(t.TempDir())
+	tempJournal(t)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"app/package.json":               "{}",
+		"app/package-lock.json":          `{"lockfileVersion": 3}`,
+		"app/node_modules/left-pad/a.js": "",
+	})
+	nm := filepath.Join(dir, "app", "node_modules")
+
+	if err := clean.CleanNodeModule(nm, []string{dir}); err != nil {
+		t.Fatalf("CleanNodeModule failed: %v", err)
+	}
+
+	entry, found, err := clean.LastRemoval(nm)
+	if err != nil || !found {
+		t.Fatalf("Expected the removal to be in the journal, got %v, %v", found, err)
+	}
+	if entry.PackageManager != "npm" || entry.LockfileHash == "" {
+		t.Errorf("Expected npm and a lockfile hash, got %+v", entry)
+	}
+	if clean.LockfileChanged(entry) {
+		t.Errorf("Expected the lockfile to be unchanged")
+	}
+
+	writeFiles(t, dir, map[string]string{"app/package-lock.json": `{"lockfileVersion": 2}`})
+	if !clean.LockfileChanged(entry) {
+		t.Errorf("Expected the lockfile change to be detected")
+	}
+}
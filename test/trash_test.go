@@ -0,0 +1,68 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/drxc00/sweepy/internal/clean"
+	"github.com/drxc00/sweepy/types"
+)
+
+func TestTrashNodeModule(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("Only the freedesktop.org trash can be redirected to a temporary directory")
+	}
+	t.Chdir(t.TempDir())
+	tempJournal(t)
+
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	writeFiles(t, dir, map[string]string{
+		"app/package.json":                 "{}",
+		"app/node_modules/lodash/index.js": "module.exports = 'lodash'",
+	})
+	nm := filepath.Join(dir, "app", "node_modules")
+
+	dest, err := clean.TrashNodeModule(nm, []string{dir})
+	if err != nil {
+		t.Fatalf("TrashNodeModule failed: %v", err)
+	}
+	if want := filepath.Join(dir, "data", "Trash", "files", "app-node_modules"); dest != want {
+		t.Errorf("Expected %s, got %s", want, dest)
+	}
+	if _, err := os.Lstat(nm); !os.IsNotExist(err) {
+		t.Errorf("Expected node_modules to be gone, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "lodash", "index.js")); err != nil {
+		t.Errorf("Expected the files in the trash: %v", err)
+	}
+
+	info, err := os.ReadFile(filepath.Join(dir, "data", "Trash", "info", "app-node_modules.trashinfo"))
+	if err != nil {
+		t.Fatalf("Expected a .trashinfo file: %v", err)
+	}
+	if !strings.Contains(string(info), "Path="+nm+"\n") {
+		t.Errorf("Expected the original path in the .trashinfo file, got %q", info)
+	}
+
+	// A second one with the same name must not overwrite the first
+	writeFiles(t, dir, map[string]string{"app/node_modules/react/index.js": "{}"})
+	second, err := clean.TrashNodeModule(nm, []string{dir})
+	if err != nil {
+		t.Fatalf("TrashNodeModule failed: %v", err)
+	}
+	if second != dest+".2" {
+		t.Errorf("Expected %s.2, got %s", dest, second)
+	}
+
+	entry, ok, err := clean.LastRemoval(nm)
+	if err != nil || !ok {
+		t.Fatalf("Expected a journal entry, got %v %v", ok, err)
+	}
+	if entry.Mode != types.JournalModeTrash || entry.Trash != second {
+		t.Errorf("Expected a trash entry pointing at %s, got %+v", second, entry)
+	}
+}
@@ -7,11 +7,20 @@ import (
 	"github.com/drxc00/sweepy/utils"
 )
 
+// Sources for the last activity date of a project
+const (
+	StalenessSourceMtime = "mtime" // Newest file modification time
+	StalenessSourceGit   = "git"   // Time of the last commit, falls back to mtime outside of git repos
+	StalenessSourceMax   = "max"   // Whichever of the two is more recent
+)
+
 type ScanContext struct {
-	Staleness  int64
-	NoCache    bool
-	ResetCache bool
-	Path       string
+	Staleness       int64
+	StalenessSource string
+	NoCache         bool
+	ResetCache      bool
+	FollowSymlinks  bool
+	Path            string
 }
 
 func NewScanContext(path string, staleness string, noCache bool, resetCache bool) ScanContext {
@@ -35,3 +44,9 @@ func NewScanContext(path string, staleness string, noCache bool, resetCache bool
 		ResetCache: resetCache,
 	}
 }
+
+// Roots returns the directories that were scanned.
+// Only artifacts under these roots may be deleted.
+func (c ScanContext) Roots() []string {
+	return []string{c.Path}
+}
@@ -0,0 +1,13 @@
+package types
+
+// IdenticalFiles is a set of files with the same content on the same filesystem.
+type IdenticalFiles struct {
+	Size  int64
+	Hash  string   // sha256 of the content
+	Paths []string // The first path is kept, the others become hard links to it
+}
+
+// Saved is the number of bytes freed by linking the files of the set.
+func (f IdenticalFiles) Saved() int64 {
+	return f.Size * int64(len(f.Paths)-1)
+}
@@ -0,0 +1,14 @@
+package types
+
+import "time"
+
+// StaleBranch is a local git branch that is a candidate for deletion.
+type StaleBranch struct {
+	Repo          string // Work tree of the repository
+	Name          string // Branch name without "refs/heads/"
+	Hash          string
+	LastCommit    time.Time
+	Staleness     int64  // In days
+	Merged        bool   // Already merged into DefaultBranch
+	DefaultBranch string // Empty when the repository has no obvious default branch
+}
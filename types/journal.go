@@ -0,0 +1,32 @@
+package types
+
+import "time"
+
+// How a node_modules directory was removed
+const (
+	JournalModeRemove  = "rm"
+	JournalModeTrash   = "trash"
+	JournalModeArchive = "archive"
+)
+
+// Outcome of a removal attempt
+const (
+	JournalResultOK     = "ok"
+	JournalResultFailed = "failed"
+)
+
+// JournalEntry records an attempt to remove a node_modules directory.
+type JournalEntry struct {
+	Time           time.Time `json:"time"`
+	Path           string    `json:"path"`  // The node_modules directory
+	Bytes          int64     `json:"bytes"` // Size of the regular files it held
+	User           string    `json:"user,omitempty"`
+	Mode           string    `json:"mode"`   // rm, trash or archive
+	Result         string    `json:"result"` // ok or failed
+	Error          string    `json:"error,omitempty"`
+	Archive        string    `json:"archive,omitempty"` // Where the archive was written, in archive mode
+	Trash          string    `json:"trash,omitempty"`   // Where the directory was moved, in trash mode
+	PackageManager string    `json:"packageManager,omitempty"`
+	Lockfile       string    `json:"lockfile,omitempty"`
+	LockfileHash   string    `json:"lockfileHash,omitempty"` // sha256 of the lockfile when node_modules was removed
+}
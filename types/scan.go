@@ -2,11 +2,41 @@ package types
 
 import "time"
 
+// Reproducibility tells if a node_modules directory can be reinstalled exactly after deleting it.
+type Reproducibility string
+
+const (
+	ReproducibilityReproducible Reproducibility = "reproducible" // A lockfile pins every version
+	ReproducibilityRisky        Reproducibility = "risky"        // package.json without a lockfile
+	ReproducibilityUnknown      Reproducibility = "unknown"      // No package.json next to node_modules
+)
+
+// ProjectMeta is what the package.json next to node_modules says about the project.
+type ProjectMeta struct {
+	Name           string   `json:"name,omitempty"`
+	Version        string   `json:"version,omitempty"`
+	PackageManager string   `json:"packageManager,omitempty"` // npm, yarn, pnpm or bun
+	NodeEngine     string   `json:"nodeEngine,omitempty"`     // engines.node range, e.g. ">=18"
+	Workspaces     []string `json:"workspaces,omitempty"`     // Workspace patterns if this is a monorepo root
+}
+
 type ScannedNodeModule struct {
-	Path         string
-	Staleness    int64 // In days
-	Size         int64
-	LastModified time.Time
+	Path         string    `json:"path"`
+	Staleness    int64     `json:"staleness"` // In days
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+	IsSymlink    bool      `json:"isSymlink"`   // node_modules itself is a symlink, only the link is removed on clean
+	GitRef       string    `json:"gitRef"`      // Branch (or short hash) checked out in the project, if it is a git repo
+	GitDirty     bool      `json:"gitDirty"`    // The project's repo has uncommitted changes
+	GitUnpushed  bool      `json:"gitUnpushed"` // The project's repo has commits that were not pushed
+
+	StalenessSource string `json:"stalenessSource,omitempty"` // What Staleness was measured from, empty for mtime in older caches
+
+	Reproducibility Reproducibility `json:"reproducibility"`
+	Project         ProjectMeta     `json:"project"`
+	Workspace       string          `json:"workspace,omitempty"` // Root directory of the workspace the project is a member of
+
+	FromCache bool `json:"-"` // Read from the cache instead of measured by this scan
 }
 
 type ScanInfo struct {
@@ -14,3 +44,44 @@ type ScanInfo struct {
 	AvgStaleness float64
 	ScanDuration time.Duration
 }
+
+// ArtifactDetails is what the TUI detail pane shows beyond the scan results.
+// It costs a walk of the whole directory, so it is only read for the row under the cursor.
+type ArtifactDetails struct {
+	Files        int64         `json:"files"`
+	ApparentSize int64         `json:"apparentSize"` // Sum of the file sizes
+	DiskSize     int64         `json:"diskSize"`     // Blocks used on disk, the apparent size where unknown
+	Markers      []string      `json:"markers"`      // Marker files and lockfiles next to node_modules
+	Packages     []PackageSize `json:"packages"`     // Biggest packages first
+	GitRepo      bool          `json:"gitRepo"`
+	GitDirty     bool          `json:"gitDirty"`
+	GitUnpushed  bool          `json:"gitUnpushed"`
+}
+
+// PackageSize is a dependency installed directly in a node_modules directory.
+type PackageSize struct {
+	Name       string `json:"name"` // Directory name, "@org/pkg" for scoped packages
+	Version    string `json:"version,omitempty"`
+	Path       string `json:"path"`
+	Size       int64  `json:"size"`       // Includes the nested copies below
+	Nested     int    `json:"nested"`     // Copies of this package installed deeper in the tree, e.g. a/node_modules/<name>
+	NestedSize int64  `json:"nestedSize"` // Bytes used by those copies
+	IsSymlink  bool   `json:"isSymlink"`  // Linked package (pnpm, npm link), its size is not counted
+}
+
+// DuplicatePackage is a package@version installed in more than one project.
+type DuplicatePackage struct {
+	Name     string   `json:"name"`
+	Version  string   `json:"version"`
+	Size     int64    `json:"size"`     // Size of one copy
+	Wasted   int64    `json:"wasted"`   // Bytes that one shared copy would save
+	Projects []string `json:"projects"` // node_modules directories with a copy
+}
+
+// SharedStoreCandidate is a project whose dependencies are mostly installed elsewhere too.
+type SharedStoreCandidate struct {
+	Path           string `json:"path"` // The node_modules directory
+	PackageManager string `json:"packageManager,omitempty"`
+	Duplicated     int    `json:"duplicated"` // Packages also installed in other projects
+	Size           int64  `json:"size"`       // Bytes of those packages in this project
+}
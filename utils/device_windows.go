@@ -0,0 +1,10 @@
+//go:build windows
+
+package utils
+
+import "os"
+
+// DeviceID is not available on Windows, volumes are identified by drive letter instead.
+func DeviceID(info os.FileInfo) (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package utils
+
+import (
+	"os"
+	"syscall"
+)
+
+// DiskUsage returns the bytes the file takes on disk, which differs from its size
+// for sparse files and because of block rounding.
+// The second return value is false when the platform does not expose it.
+func DiskUsage(info os.FileInfo) (int64, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return int64(st.Blocks) * 512, true // st_blocks is always in 512 byte units
+}
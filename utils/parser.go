@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 func ParseStalenessFlagValue(stalenessFlag string) (int64, error) {
@@ -22,3 +23,31 @@ func ParseStalenessFlagValue(stalenessFlag string) (int64, error) {
 
 	return staleness, nil
 }
+
+var sizeUnits = map[string]float64{
+	"":   1,
+	"B":  1,
+	"KB": 1024,
+	"MB": 1024 * 1024,
+	"GB": 1024 * 1024 * 1024,
+	"TB": 1024 * 1024 * 1024 * 1024,
+}
+
+var sizePattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([A-Za-z]*)$`)
+
+// ParseSize parses a size such as "500MB", "1.5 GB" or "2048" (bytes). Units are powers of 1024.
+func ParseSize(s string) (int64, error) {
+	match := sizePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	unit, ok := sizeUnits[strings.ToUpper(match[2])]
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit %q: use B, KB, MB, GB or TB", match[2])
+	}
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(value * unit), nil
+}
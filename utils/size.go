@@ -1,6 +1,9 @@
 package utils
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 func FormatSize(size int64) string {
 	switch {
@@ -12,3 +15,20 @@ func FormatSize(size int64) string {
 		return fmt.Sprintf("%d bytes", size)
 	}
 }
+
+// ProgressBar draws a bar width characters wide, filled by done out of total.
+func ProgressBar(done, total int64, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = int(float64(width) * float64(min(done, total)) / float64(total))
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// Percent returns done out of total as a whole percentage, capped at 100.
+func Percent(done, total int64) int {
+	if total <= 0 {
+		return 0
+	}
+	return int(min(done, total) * 100 / total)
+}
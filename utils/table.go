@@ -9,21 +9,44 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+// StalenessScale is how ColorCodedStaleness colors a staleness: fresh up to Stale days,
+// then stale, old above Old days and ancient above Ancient days.
+type StalenessScale struct {
+	Stale, Old, Ancient                            int64
+	FreshColor, StaleColor, OldColor, AncientColor lipgloss.TerminalColor
+}
+
+var stalenessScale = StalenessScale{
+	Stale:        90,
+	Old:          180,
+	Ancient:      365,
+	FreshColor:   lipgloss.Color("2"),  // Green
+	StaleColor:   lipgloss.Color("14"), // Cyan
+	OldColor:     lipgloss.Color("11"), // Yellow
+	AncientColor: lipgloss.Color("9"),  // Red
+}
+
+// SetStalenessScale replaces the thresholds and colors used by ColorCodedStaleness.
+func SetStalenessScale(scale StalenessScale) {
+	stalenessScale = scale
+}
+
 func ColorCodedStaleness(staleness int64) string {
-	var style lipgloss.Style
+	var color lipgloss.TerminalColor
 
 	// Apply different colors based on the staleness value
-	if staleness > 365 {
-		style = lipgloss.NewStyle().Foreground(lipgloss.Color("9")) // Red
-	} else if staleness > 180 {
-		style = lipgloss.NewStyle().Foreground(lipgloss.Color("11")) // Yellow
-	} else if staleness > 90 {
-		style = lipgloss.NewStyle().Foreground(lipgloss.Color("14")) // Cyan
+	s := stalenessScale
+	if staleness > s.Ancient {
+		color = s.AncientColor
+	} else if staleness > s.Old {
+		color = s.OldColor
+	} else if staleness > s.Stale {
+		color = s.StaleColor
 	} else {
-		style = lipgloss.NewStyle().Foreground(lipgloss.Color("2")) // Green
+		color = s.FreshColor
 	}
 
-	return style.Render(fmt.Sprintf("%d days", staleness))
+	return lipgloss.NewStyle().Foreground(color).Render(fmt.Sprintf("%d days", staleness))
 }
 
 func FormatPath(p string, r string) string {